@@ -0,0 +1,61 @@
+package server
+
+import "net"
+
+// AccessRule describes IP allow & deny lists, entries can be either IPs or CIDRs.
+type AccessRule struct {
+	Allow []string `json:"allow,omitempty"` // Empty means everyone is allowed
+	Deny  []string `json:"deny,omitempty"`
+}
+
+// accessRule describes a parsed AccessRule.
+type accessRule struct {
+	allow []*net.IPNet
+	deny  []*net.IPNet
+}
+
+// parseAccessRules parses access rules.
+//
+// @param
+// - rules {map[string]AccessRule} (path's prefix -> access rule)
+//
+// @return
+// - parsedRules {map[string]accessRule} (path's prefix -> parsed access rule)
+func parseAccessRules(rules map[string]AccessRule) map[string]accessRule {
+	parsedRules := make(map[string]accessRule, len(rules))
+	for prefix, rule := range rules {
+		parsedRules[prefix] = accessRule{
+			allow: parseNetworks(rule.Allow),
+			deny:  parseNetworks(rule.Deny),
+		}
+	}
+	return parsedRules
+}
+
+// isAccessAllowed validates client's IP against every rule that matches request's path, global rule
+// should be defined with "/" prefix.
+//
+// @param
+// - path {string} (request's cleaned path)
+// - ip {string} (client's IP)
+//
+// @return
+// - flag {bool} (indicate flag if client is allowed or not)
+func isAccessAllowed(path string, ip string) bool {
+	Cfg.mutex.RLock()
+	defer Cfg.mutex.RUnlock()
+
+	for prefix, rule := range Cfg.accessRules {
+		if !hasPathPrefix(path, prefix) {
+			continue
+		}
+
+		if containsIP(rule.deny, ip) {
+			return false
+		}
+		if len(rule.allow) > 0 && !containsIP(rule.allow, ip) {
+			return false
+		}
+	}
+	return true
+}
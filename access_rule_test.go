@@ -0,0 +1,76 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/phuc0302/go-server/expected_format"
+	"github.com/phuc0302/go-server/util"
+)
+
+func Test_AccessRules(t *testing.T) {
+	defer os.Remove(Debug)
+	Initialize(true)
+
+	// Setup test server
+	BindGet("/sample", func(c *RequestContext) {
+		c.OutputText(util.Status200(), c.ClientIP())
+	})
+	GroupRoute("/admin", func() {
+		BindGet("/sample", func(c *RequestContext) {
+			c.OutputText(util.Status200(), c.ClientIP())
+		})
+	})
+
+	ts := httptest.NewServer(ServeHTTP())
+	defer ts.Close()
+
+	get := func(path string, ip string) int {
+		request, _ := http.NewRequest("GET", ts.URL+path, nil)
+		request.Header.Set("X-Forwarded-For", ip)
+
+		response, _ := http.DefaultClient.Do(request)
+		return response.StatusCode
+	}
+
+	// [Test 1] No rules
+	if status := get("/admin/sample", "198.51.100.7"); status != 200 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 200, status)
+	}
+
+	// [Test 2] Reload rules from configuration file
+	Cfg.AccessRules = map[string]AccessRule{
+		"/":          {Deny: []string{"198.51.100.0/24"}},
+		"/admin":     {Allow: []string{"10.8.0.0/16"}},
+		"/resources": {Allow: []string{"10.8.0.1"}},
+	}
+	Cfg.Save()
+	Cfg.AccessRules = nil
+
+	if err := ReloadConfig(); err != nil {
+		t.Errorf(expectedFormat.Nil)
+	}
+
+	if status := get("/sample", "198.51.100.7"); status != 403 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 403, status)
+	}
+	if status := get("/sample", "203.0.113.1"); status != 200 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 200, status)
+	}
+	if status := get("/admin/sample", "203.0.113.1"); status != 403 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 403, status)
+	}
+	if status := get("/admin/sample", "10.8.3.4"); status != 200 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 200, status)
+	}
+	if status := get("/resources/LICENSE", "10.8.3.4"); status != 403 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 403, status)
+	}
+
+	// [Test 3] Rule matches on path segment boundary
+	if !isAccessAllowed("/administrator", "203.0.113.1") {
+		t.Errorf(expectedFormat.BoolButFoundBool, true, false)
+	}
+}
@@ -0,0 +1,187 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/phuc0302/go-server/util"
+)
+
+const (
+	// APIKeyHeader is the header that carries client's API key.
+	APIKeyHeader = "X-API-Key"
+
+	// APIKeyQuery is the query parameter that carries client's API key.
+	APIKeyQuery = "api_key"
+
+	// APIKeyName is the extra's key that holds authenticated key's name.
+	APIKeyName = "api_key_name"
+)
+
+// apiKeyChallenge is the authentication challenge that will be sent along with 401.
+var apiKeyChallenge = fmt.Sprintf("APIKey header=%q", APIKeyHeader)
+
+// apiKeyScopesKey is the extra's key that holds authenticated key's scopes.
+const apiKeyScopesKey = "api_key_scopes"
+
+// APIKeyValidator defines type alias for API key validator.
+//
+// @param
+// - hash {string} (the hex encoded SHA-256 of the key that client provided)
+//
+// @return
+// - name {string} (the key's name)
+// - scopes {[]string} (the key's scopes)
+// - ok {bool} (indicate flag if key is valid or not)
+type APIKeyValidator func(hash string) (name string, scopes []string, ok bool)
+
+// apiKey describes an API key's entry.
+type apiKey struct {
+	name   string
+	scopes []string
+}
+
+// apiKeyFile describes an API keys file that will be reloaded whenever it is changed.
+type apiKeyFile struct {
+	file  reloadableFile
+	keys  map[string]apiKey
+	mutex sync.RWMutex
+}
+
+// HashAPIKey generates hex encoded SHA-256 of an API key, it is the form that key stores keep.
+func HashAPIKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
+
+// APIKeyFile generates API key adapter that validates key against keys file. Each line of the file
+// has format "name:sha256:scope1,scope2", the file will be reloaded whenever it is changed.
+//
+// @param
+// - filePath {string} (path to keys file)
+//
+// @return
+// - adapter {Adapter} (the API key adapter)
+func APIKeyFile(filePath string) Adapter {
+	file := &apiKeyFile{file: reloadableFile{name: "API keys", filePath: filePath}}
+	file.reload()
+
+	return APIKeyFunc(file.validate)
+}
+
+// APIKeyFunc generates API key adapter that validates key with user's callback.
+//
+// @param
+// - validator {APIKeyValidator} (the key validator)
+//
+// @return
+// - adapter {Adapter} (the API key adapter)
+func APIKeyFunc(validator APIKeyValidator) Adapter {
+	/* Condition validation: only accept function */
+	if validator == nil {
+		panic("API key validator must not be nil.")
+	}
+
+	return func(f HandleContextFunc) HandleContextFunc {
+		return func(c *RequestContext) {
+			key := c.request.Header.Get(APIKeyHeader)
+			if len(key) == 0 {
+				key = c.request.URL.Query().Get(APIKeyQuery)
+			}
+
+			/* Condition validation: validate key */
+			if len(key) == 0 {
+				c.OutputHeader("WWW-Authenticate", apiKeyChallenge)
+				c.outputProblem(util.Status401())
+				return
+			}
+			name, scopes, ok := validator(HashAPIKey(key))
+			if !ok {
+				c.OutputHeader("WWW-Authenticate", apiKeyChallenge)
+				c.outputProblem(util.Status401())
+				return
+			}
+
+			c.SetExtra(APIKeyName, name)
+			c.SetExtra(apiKeyScopesKey, scopes)
+			f(c)
+		}
+	}
+}
+
+// RequireScopes generates adapter that rejects request with 403 if authenticated API key does not
+// have all scopes. It must be used after API key adapter.
+//
+// @param
+// - scopes {...string} (the required scopes)
+//
+// @return
+// - adapter {Adapter} (the scopes adapter)
+func RequireScopes(scopes ...string) Adapter {
+	return func(f HandleContextFunc) HandleContextFunc {
+		return func(c *RequestContext) {
+			for _, scope := range scopes {
+				if !c.HasScope(scope) {
+					c.outputProblem(util.Status403())
+					return
+				}
+			}
+			f(c)
+		}
+	}
+}
+
+// Scopes returns authenticated API key's scopes.
+func (c *RequestContext) Scopes() []string {
+	scopes, _ := c.GetExtra(apiKeyScopesKey).([]string)
+	return scopes
+}
+
+// HasScope checks if authenticated API key has scope or not.
+func (c *RequestContext) HasScope(scope string) bool {
+	for _, s := range c.Scopes() {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// validate looks up key's entry by its hash.
+func (a *apiKeyFile) validate(hash string) (string, []string, bool) {
+	a.reload()
+
+	a.mutex.RLock()
+	key, ok := a.keys[hash]
+	a.mutex.RUnlock()
+
+	return key.name, key.scopes, ok
+}
+
+// reload reads keys file again if it had been changed since last read.
+func (a *apiKeyFile) reload() {
+	a.file.reload(func(lines []string) {
+		keys := make(map[string]apiKey)
+		for _, line := range lines {
+			tokens := strings.SplitN(line, ":", 3)
+			if len(tokens) < 2 || len(tokens[1]) != sha256.Size*2 {
+				logrus.Warningf("skip invalid API key entry: %s", tokens[0])
+				continue
+			}
+
+			key := apiKey{name: tokens[0]}
+			if len(tokens) == 3 {
+				key.scopes = splitHeaderList([]string{tokens[2]})
+			}
+			keys[strings.ToLower(tokens[1])] = key
+		}
+
+		a.mutex.Lock()
+		a.keys = keys
+		a.mutex.Unlock()
+	})
+}
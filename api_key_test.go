@@ -0,0 +1,64 @@
+package server
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/phuc0302/go-server/expected_format"
+	"github.com/phuc0302/go-server/util"
+)
+
+func Test_APIKeyFile(t *testing.T) {
+	filePath := "api_keys"
+	defer os.Remove(filePath)
+	ioutil.WriteFile(filePath, []byte(fmt.Sprintf("# API keys\nreporter:%s:orders.read\nadmin:%s:orders.read,orders.write\n",
+		HashAPIKey("reporter-key"), HashAPIKey("admin-key"))), 0600)
+
+	// Create test server
+	handler := Adapt(func(c *RequestContext) {
+		c.OutputText(util.Status200(), fmt.Sprintf("%s", c.GetExtra(APIKeyName)))
+	}, APIKeyFile(filePath), RequireScopes("orders.write"))
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler(CreateContext(w, r))
+	}))
+	defer ts.Close()
+
+	get := func(url string, key string) int {
+		request, _ := http.NewRequest("GET", url, nil)
+		if len(key) > 0 {
+			request.Header.Set(APIKeyHeader, key)
+		}
+		response, _ := http.DefaultClient.Do(request)
+		return response.StatusCode
+	}
+
+	// [Test 1] Missing & unknown key
+	if code := get(ts.URL, ""); code != 401 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 401, code)
+	}
+	response, _ := http.Get(ts.URL)
+	if challenge := response.Header.Get("WWW-Authenticate"); challenge != `APIKey header="X-API-Key"` {
+		t.Errorf(expectedFormat.StringButFoundString, `APIKey header="X-API-Key"`, challenge)
+	}
+	if code := get(ts.URL, "unknown-key"); code != 401 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 401, code)
+	}
+
+	// [Test 2] Missing scope
+	if code := get(ts.URL, "reporter-key"); code != 403 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 403, code)
+	}
+
+	// [Test 3] Valid key from header & query
+	if code := get(ts.URL, "admin-key"); code != 200 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 200, code)
+	}
+	if code := get(ts.URL+"?"+APIKeyQuery+"=admin-key", ""); code != 200 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 200, code)
+	}
+}
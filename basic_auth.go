@@ -0,0 +1,123 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/phuc0302/go-server/util"
+)
+
+// BasicAuthUser is the extra's key that holds authenticated username.
+const BasicAuthUser = "basic_auth_user"
+
+// BasicAuthValidator defines type alias for basic auth credentials validator.
+//
+// @param
+// - username {string} (the username that client provided)
+// - password {string} (the password that client provided)
+//
+// @return
+// - ok {bool} (indicate flag if credentials are valid or not)
+type BasicAuthValidator func(username string, password string) bool
+
+// htpasswd describes a htpasswd file that will be reloaded whenever it is changed.
+type htpasswd struct {
+	file  reloadableFile
+	users map[string]string
+	mutex sync.RWMutex
+}
+
+// BasicAuthFile generates basic auth adapter that validates credentials against htpasswd file.
+// Only bcrypt entries are accepted, the file will be reloaded whenever it is changed.
+//
+// @param
+// - realm {string} (the realm that will be sent along with authentication challenge)
+// - filePath {string} (path to htpasswd file)
+//
+// @return
+// - adapter {Adapter} (the basic auth adapter)
+func BasicAuthFile(realm string, filePath string) Adapter {
+	file := &htpasswd{file: reloadableFile{name: "htpasswd", filePath: filePath}}
+	file.reload()
+
+	return BasicAuthFunc(realm, file.validate)
+}
+
+// BasicAuthFunc generates basic auth adapter that validates credentials with user's callback.
+//
+// @param
+// - realm {string} (the realm that will be sent along with authentication challenge)
+// - validator {BasicAuthValidator} (the credentials validator)
+//
+// @return
+// - adapter {Adapter} (the basic auth adapter)
+func BasicAuthFunc(realm string, validator BasicAuthValidator) Adapter {
+	/* Condition validation: only accept function */
+	if validator == nil {
+		panic("Basic auth validator must not be nil.")
+	}
+	challenge := fmt.Sprintf("Basic realm=%q, charset=\"UTF-8\"", realm)
+
+	return func(f HandleContextFunc) HandleContextFunc {
+		return func(c *RequestContext) {
+			username, password, ok := c.BasicAuth()
+
+			/* Condition validation: validate credentials */
+			if !ok || !validator(username, password) {
+				c.OutputHeader("WWW-Authenticate", challenge)
+				c.outputProblem(util.Status401())
+				return
+			}
+
+			c.SetExtra(BasicAuthUser, username)
+			f(c)
+		}
+	}
+}
+
+// validate validates credentials against htpasswd's entries.
+//
+// @param
+// - username {string} (the username that client provided)
+// - password {string} (the password that client provided)
+//
+// @return
+// - ok {bool} (indicate flag if credentials are valid or not)
+func (h *htpasswd) validate(username string, password string) bool {
+	h.reload()
+
+	h.mutex.RLock()
+	hash, ok := h.users[username]
+	h.mutex.RUnlock()
+
+	if !ok {
+		return false
+	}
+	return util.ComparePassword(hash, password)
+}
+
+// reload reads htpasswd file again if it had been changed since last read.
+func (h *htpasswd) reload() {
+	h.file.reload(func(lines []string) {
+		users := make(map[string]string)
+		for _, line := range lines {
+			tokens := strings.SplitN(line, ":", 2)
+			if len(tokens) != 2 || !isBcryptHash(tokens[1]) {
+				logrus.Warningf("skip unsupported htpasswd entry: %s", tokens[0])
+				continue
+			}
+			users[tokens[0]] = tokens[1]
+		}
+
+		h.mutex.Lock()
+		h.users = users
+		h.mutex.Unlock()
+	})
+}
+
+// isBcryptHash checks if a htpasswd's hash had been generated by bcrypt or not.
+func isBcryptHash(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
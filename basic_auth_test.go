@@ -0,0 +1,103 @@
+package server
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/phuc0302/go-server/expected_format"
+	"github.com/phuc0302/go-server/util"
+)
+
+func Test_BasicAuthFunc(t *testing.T) {
+	adapter := BasicAuthFunc("Restricted", func(username string, password string) bool {
+		return username == "admin" && password == "12345678"
+	})
+
+	// Create test server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		context := CreateContext(w, r)
+		Adapt(func(c *RequestContext) {
+			c.OutputText(util.Status200(), fmt.Sprintf("%s", c.GetExtra(BasicAuthUser)))
+		}, adapter)(context)
+	}))
+	defer ts.Close()
+
+	// [Test 1] Missing credentials
+	response, _ := http.Get(ts.URL)
+	if response.StatusCode != 401 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 401, response.StatusCode)
+	}
+	if challenge := response.Header.Get("WWW-Authenticate"); challenge != "Basic realm=\"Restricted\", charset=\"UTF-8\"" {
+		t.Errorf(expectedFormat.StringButFoundString, "Basic realm=\"Restricted\", charset=\"UTF-8\"", challenge)
+	}
+
+	// [Test 2] Valid credentials
+	request, _ := http.NewRequest("GET", ts.URL, nil)
+	request.SetBasicAuth("admin", "12345678")
+
+	response, _ = http.DefaultClient.Do(request)
+	if response.StatusCode != 200 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 200, response.StatusCode)
+	} else {
+		bytes, _ := ioutil.ReadAll(response.Body)
+		if string(bytes) != "admin" {
+			t.Errorf(expectedFormat.StringButFoundString, "admin", string(bytes))
+		}
+	}
+}
+
+func Test_BasicAuthFile(t *testing.T) {
+	filePath := "htpasswd"
+	defer os.Remove(filePath)
+
+	hash, _ := util.EncryptPassword("12345678")
+	ioutil.WriteFile(filePath, []byte(fmt.Sprintf("# users\nadmin:%s\nlegacy:{SHA}abcdef\n", hash)), 0644)
+
+	adapter := BasicAuthFile("Restricted", filePath)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		context := CreateContext(w, r)
+		Adapt(func(c *RequestContext) {
+			c.OutputText(util.Status200(), "")
+		}, adapter)(context)
+	}))
+	defer ts.Close()
+
+	// [Test 1] Valid credentials
+	request, _ := http.NewRequest("GET", ts.URL, nil)
+	request.SetBasicAuth("admin", "12345678")
+	if response, _ := http.DefaultClient.Do(request); response.StatusCode != 200 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 200, response.StatusCode)
+	}
+
+	// [Test 2] Unsupported entry
+	request.SetBasicAuth("legacy", "12345678")
+	if response, _ := http.DefaultClient.Do(request); response.StatusCode != 401 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 401, response.StatusCode)
+	}
+
+	// [Test 3] File had been changed
+	ioutil.WriteFile(filePath, []byte(fmt.Sprintf("guest:%s\n", hash)), 0644)
+	modTime := time.Now().Add(time.Minute)
+	os.Chtimes(filePath, modTime, modTime)
+
+	request.SetBasicAuth("admin", "12345678")
+	if response, _ := http.DefaultClient.Do(request); response.StatusCode != 401 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 401, response.StatusCode)
+	}
+
+	request.SetBasicAuth("guest", "12345678")
+	if response, _ := http.DefaultClient.Do(request); response.StatusCode != 200 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 200, response.StatusCode)
+	}
+
+	// [Test 4] Removed file revokes every user
+	os.Remove(filePath)
+	if response, _ := http.DefaultClient.Do(request); response.StatusCode != 401 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 401, response.StatusCode)
+	}
+}
@@ -0,0 +1,74 @@
+package server
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+
+	"github.com/phuc0302/go-server/util"
+)
+
+// bodyLimit returns maximum body size for request. Group or route's override takes priority over
+// content type's override, which takes priority over global default.
+//
+// @param
+// - path {string} (request's cleaned path)
+// - contentType {string} (request's content type)
+//
+// @return
+// - limit {int64} (maximum body size in bytes, 0 means unlimited)
+func bodyLimit(path string, contentType string) int64 {
+	// Find the longest matched group or route
+	matched := ""
+	for prefix := range Cfg.BodySizes {
+		if hasPathPrefix(path, prefix) && len(prefix) > len(matched) {
+			matched = prefix
+		}
+	}
+	if len(matched) > 0 {
+		return Cfg.BodySizes[matched]
+	}
+
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		if limit, ok := Cfg.ContentBodySizes[mediaType]; ok {
+			return limit
+		}
+	}
+	return Cfg.MaxBodySize
+}
+
+// limitBody enforces maximum body size on request, oversized request will be rejected with 413.
+//
+// @param
+// - w {http.ResponseWriter} (the response writer)
+// - r {http.Request} (the request)
+// - path {string} (request's cleaned path)
+func limitBody(w http.ResponseWriter, r *http.Request, path string) {
+	limit := bodyLimit(path, r.Header.Get("Content-Type"))
+	if limit <= 0 || r.Body == nil {
+		return
+	}
+
+	/* Condition validation: reject fast if declared length is already too large */
+	if r.ContentLength > limit {
+		panic(util.Status413())
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, limit)
+}
+
+// isBodyTooLarge checks if error was caused by exceeding maximum body size.
+func isBodyTooLarge(err error) bool {
+	return errors.As(err, new(*http.MaxBytesError))
+}
+
+// bodyErrorStatus converts body's read error into status, oversized body never reaches here since
+// readBody panics with 413.
+//
+// @param
+// - err {error} (error returned by readBody)
+//
+// @return
+// - status {Status} (the status that should be returned to client)
+func bodyErrorStatus(err error) *util.Status {
+	return util.Status400WithDescription("Could not read request's body.")
+}
@@ -0,0 +1,100 @@
+package server
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/phuc0302/go-server/expected_format"
+	"github.com/phuc0302/go-server/util"
+)
+
+func Test_BodyLimit(t *testing.T) {
+	defer os.Remove(Debug)
+	Initialize(true)
+
+	// Setup limits
+	Cfg.MaxBodySize = 16
+	Cfg.BodySizes = map[string]int64{"/upload": 1024}
+	Cfg.ContentBodySizes = map[string]int64{"application/json": 32}
+
+	// Setup test server
+	handler := func(c *RequestContext) {
+		// Typical handler turns any bind's error into 400
+		var model map[string]string
+		if _, err := c.BindJSON(&model); err != nil && strings.HasPrefix(c.HeaderValue("Content-Type"), "application/json") {
+			c.OutputStatus(util.Status400())
+			return
+		}
+		c.OutputJSON(util.Status200(), c.QueryParams)
+	}
+	BindPost("/sample", handler)
+	BindPost("/upload", handler)
+	BindPost("/uploads", handler)
+
+	ts := httptest.NewServer(ServeHTTP())
+	defer ts.Close()
+
+	post := func(path string, contentType string, body string, chunked bool) int {
+		reader := ioutil.NopCloser(strings.NewReader(body))
+		request, _ := http.NewRequest("POST", fmt.Sprintf("%s%s", ts.URL, path), reader)
+		request.Header.Set("Content-Type", contentType)
+		if !chunked {
+			request.ContentLength = int64(len(body))
+		}
+
+		response, _ := http.DefaultClient.Do(request)
+		return response.StatusCode
+	}
+	form := "key=" + strings.Repeat("a", 20)
+	json := "{\"key\":\"" + strings.Repeat("a", 30) + "\"}"
+
+	// [Test 1] Global default
+	if status := post("/sample", "application/x-www-form-urlencoded", form, false); status != 413 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 413, status)
+	}
+	if status := post("/sample", "application/x-www-form-urlencoded", form, true); status != 413 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 413, status)
+	}
+	if status := post("/sample", "application/x-www-form-urlencoded", "key=a", true); status != 200 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 200, status)
+	}
+
+	// [Test 2] Content type's override
+	if status := post("/sample", "application/json; charset=utf-8", json, true); status != 413 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 413, status)
+	}
+	if status := post("/sample", "application/json", "{\"key\":\"a\"}", true); status != 200 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 200, status)
+	}
+
+	// [Test 3] Route's override
+	if status := post("/upload", "application/json", json, true); status != 200 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 200, status)
+	}
+	if status := post("/uploads", "application/json", json, true); status != 413 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 413, status)
+	}
+}
+
+func Test_BindJSON_BodyTooLarge(t *testing.T) {
+	request, _ := http.NewRequest("POST", "/sample", strings.NewReader("{\"key\":\"value\"}"))
+	request.Header.Set("Content-Type", "application/json")
+
+	recorder := httptest.NewRecorder()
+	request.Body = http.MaxBytesReader(recorder, request.Body, 4)
+	context := CreateContext(recorder, request)
+
+	defer func() {
+		if status, ok := recover().(*util.Status); !ok || status.Code != 413 {
+			t.Error(expectedFormat.Panic)
+		}
+	}()
+
+	var model map[string]string
+	context.BindJSON(&model)
+}
@@ -0,0 +1,244 @@
+package server
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// clientAddress describes the real client behind trusted proxies.
+type clientAddress struct {
+	ip     string
+	scheme string
+	host   string
+}
+
+// ClientIP returns real client's IP, forwarded headers are only accepted from trusted proxies.
+func (c *RequestContext) ClientIP() string {
+	return c.clientAddress().ip
+}
+
+// Scheme returns the scheme that client used to reach the server, either http or https.
+func (c *RequestContext) Scheme() string {
+	return c.clientAddress().scheme
+}
+
+// Host returns the host that client used to reach the server.
+func (c *RequestContext) Host() string {
+	return c.clientAddress().host
+}
+
+// clientAddress resolves real client's address once per request.
+func (c *RequestContext) clientAddress() *clientAddress {
+	if c.address == nil {
+		c.address = resolveClientAddress(c.request)
+	}
+	return c.address
+}
+
+// resolveClientAddress resolves real client's address from request. Forwarded hops are walked from
+// right to left & the first untrusted hop is the client.
+//
+// @param
+// - r {http.Request} (the request)
+//
+// @return
+// - address {clientAddress} (the client's address)
+func resolveClientAddress(r *http.Request) *clientAddress {
+	address := &clientAddress{
+		ip:     r.RemoteAddr,
+		scheme: "http",
+		host:   r.Host,
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		address.ip = host
+	}
+	if r.TLS != nil {
+		address.scheme = "https"
+	}
+
+	/* Condition validation: only trust forwarded headers from trusted proxies */
+	if !isTrustedProxy(address.ip) {
+		return address
+	}
+
+	if forwarded := r.Header["Forwarded"]; len(forwarded) > 0 {
+		elements := parseForwarded(forwarded)
+		if idx := findClientHop(len(elements), func(i int) string { return elements[i]["for"] }); idx >= 0 {
+			element := elements[idx]
+			address.ip = element["for"]
+
+			if proto := element["proto"]; len(proto) > 0 {
+				address.scheme = strings.ToLower(proto)
+			}
+			if host := element["host"]; len(host) > 0 {
+				address.host = host
+			}
+		}
+		return address
+	}
+
+	// Number of trusted proxies that forwarded request, each of them appends its own values
+	hops := 1
+	if forwardedFor := splitHeaderList(r.Header["X-Forwarded-For"]); len(forwardedFor) > 0 {
+		if idx := findClientHop(len(forwardedFor), func(i int) string { return forwardedFor[i] }); idx >= 0 {
+			address.ip = forwardedFor[idx]
+			hops = len(forwardedFor) - idx
+		}
+	} else if realIP := strings.TrimSpace(r.Header.Get("X-Real-IP")); net.ParseIP(realIP) != nil {
+		address.ip = realIP
+	}
+
+	if proto := strings.ToLower(findForwardedValue(splitHeaderList(r.Header["X-Forwarded-Proto"]), hops)); proto == "http" || proto == "https" {
+		address.scheme = proto
+	}
+	if host := findForwardedValue(splitHeaderList(r.Header["X-Forwarded-Host"]), hops); len(host) > 0 {
+		address.host = host
+	}
+	return address
+}
+
+// findForwardedValue walks values from right to left & returns the one that the outermost trusted
+// proxy appended, values on its left are controlled by client.
+//
+// @param
+// - values {[]string} (X-Forwarded-Proto or X-Forwarded-Host's values)
+// - hops {int} (number of trusted proxies that forwarded request)
+//
+// @return
+// - value {string} (the trusted value, empty if there is none)
+func findForwardedValue(values []string, hops int) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	// Proxies that overwrite instead of append leave fewer values than hops, all of them are trusted
+	idx := len(values) - hops
+	if idx < 0 {
+		idx = 0
+	}
+	return values[idx]
+}
+
+// findClientHop walks hops from right to left & returns the first untrusted one.
+//
+// @param
+// - count {int} (number of hops)
+// - hop {func} (returns hop's address at index)
+//
+// @return
+// - idx {int} (index of client's hop, -1 if chain contains invalid address)
+func findClientHop(count int, hop func(i int) string) int {
+	for i := count - 1; i >= 0; i-- {
+		ip := normalizeIP(hop(i))
+		if net.ParseIP(ip) == nil {
+			return -1
+		}
+
+		if !isTrustedProxy(ip) || i == 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// isTrustedProxy checks if an IP belongs to trusted proxies or not.
+func isTrustedProxy(ip string) bool {
+	if Cfg == nil {
+		return false
+	}
+	return containsIP(Cfg.trustedProxies, ip)
+}
+
+// parseForwarded parses RFC 7239 Forwarded header into elements.
+//
+// @param
+// - values {[]string} (all Forwarded header's values)
+//
+// @return
+// - elements {[]map[string]string} (a list of forwarded elements, parameter's name in lower case)
+func parseForwarded(values []string) []map[string]string {
+	var elements []map[string]string
+	for _, value := range splitHeaderList(values) {
+		element := make(map[string]string)
+
+		for _, pair := range strings.Split(value, ";") {
+			tokens := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+			if len(tokens) != 2 {
+				continue
+			}
+
+			name := strings.ToLower(strings.TrimSpace(tokens[0]))
+			element[name] = strings.Trim(strings.TrimSpace(tokens[1]), "\"")
+		}
+		if name, ok := element["for"]; ok {
+			element["for"] = normalizeIP(name)
+		}
+		elements = append(elements, element)
+	}
+	return elements
+}
+
+// normalizeIP removes port & IPv6's brackets from node's address.
+func normalizeIP(node string) string {
+	node = strings.TrimSpace(node)
+	if host, _, err := net.SplitHostPort(node); err == nil {
+		return host
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(node, "["), "]")
+}
+
+// splitHeaderList splits comma separated header's values.
+func splitHeaderList(values []string) []string {
+	var list []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); len(item) > 0 {
+				list = append(list, item)
+			}
+		}
+	}
+	return list
+}
+
+// parseNetworks parses a list of IPs & CIDRs, invalid entries will be skipped.
+//
+// @param
+// - values {[]string} (a list of IPs or CIDRs)
+//
+// @return
+// - networks {[]net.IPNet} (a list of networks)
+func parseNetworks(values []string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(values))
+	for _, value := range values {
+		if !strings.Contains(value, "/") {
+			if ip := net.ParseIP(value); ip == nil {
+				continue
+			} else if ip.To4() != nil {
+				value = value + "/32"
+			} else {
+				value = value + "/128"
+			}
+		}
+
+		if _, network, err := net.ParseCIDR(value); err == nil {
+			networks = append(networks, network)
+		}
+	}
+	return networks
+}
+
+// containsIP checks if an IP belongs to any of networks.
+func containsIP(networks []*net.IPNet, value string) bool {
+	ip := net.ParseIP(value)
+	if ip == nil {
+		return false
+	}
+
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
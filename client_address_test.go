@@ -0,0 +1,90 @@
+package server
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/phuc0302/go-server/expected_format"
+)
+
+func Test_ClientAddress(t *testing.T) {
+	config := Cfg
+	defer func() { Cfg = config }()
+
+	Cfg = &Config{TrustedProxies: []string{"10.0.0.0/8", "192.0.2.1", "2001:db8::/32"}}
+	Cfg.prepare()
+
+	resolve := func(remoteAddr string, headers map[string]string) *RequestContext {
+		request := httptest.NewRequest("GET", "http://example.com/", nil)
+		request.RemoteAddr = remoteAddr
+		for name, value := range headers {
+			request.Header.Set(name, value)
+		}
+		return CreateContext(httptest.NewRecorder(), request)
+	}
+
+	// [Test 1] Untrusted peer can not spoof headers
+	c := resolve("203.0.113.9:1234", map[string]string{"X-Forwarded-For": "1.1.1.1", "X-Forwarded-Proto": "https"})
+	if c.ClientIP() != "203.0.113.9" {
+		t.Errorf(expectedFormat.StringButFoundString, "203.0.113.9", c.ClientIP())
+	}
+	if c.Scheme() != "http" {
+		t.Errorf(expectedFormat.StringButFoundString, "http", c.Scheme())
+	}
+
+	// [Test 2] X-Forwarded-For is walked from right to left
+	c = resolve("192.0.2.1:1234", map[string]string{
+		"X-Forwarded-For":   "1.1.1.1, 198.51.100.7, 10.0.0.2",
+		"X-Forwarded-Proto": "https",
+		"X-Forwarded-Host":  "api.example.com",
+	})
+	if c.ClientIP() != "198.51.100.7" {
+		t.Errorf(expectedFormat.StringButFoundString, "198.51.100.7", c.ClientIP())
+	}
+	if c.Scheme() != "https" {
+		t.Errorf(expectedFormat.StringButFoundString, "https", c.Scheme())
+	}
+	if c.Host() != "api.example.com" {
+		t.Errorf(expectedFormat.StringButFoundString, "api.example.com", c.Host())
+	}
+
+	// [Test 3] Client's values on the left of trusted proxy's values are ignored
+	c = resolve("192.0.2.1:1234", map[string]string{
+		"X-Forwarded-For":   "198.51.100.7, 10.0.0.2",
+		"X-Forwarded-Proto": "gopher, https, http",
+		"X-Forwarded-Host":  "evil.example.com, api.example.com, internal",
+	})
+	if c.Scheme() != "https" {
+		t.Errorf(expectedFormat.StringButFoundString, "https", c.Scheme())
+	}
+	if c.Host() != "api.example.com" {
+		t.Errorf(expectedFormat.StringButFoundString, "api.example.com", c.Host())
+	}
+
+	// [Test 4] Forwarded takes priority
+	c = resolve("10.1.2.3:1234", map[string]string{
+		"Forwarded":       "for=198.51.100.7;proto=https;host=www.example.com, for=\"[2001:db8::1]:4711\"",
+		"X-Forwarded-For": "1.1.1.1",
+	})
+	if c.ClientIP() != "198.51.100.7" {
+		t.Errorf(expectedFormat.StringButFoundString, "198.51.100.7", c.ClientIP())
+	}
+	if c.Scheme() != "https" {
+		t.Errorf(expectedFormat.StringButFoundString, "https", c.Scheme())
+	}
+	if c.Host() != "www.example.com" {
+		t.Errorf(expectedFormat.StringButFoundString, "www.example.com", c.Host())
+	}
+
+	// [Test 5] X-Real-IP
+	c = resolve("10.1.2.3:1234", map[string]string{"X-Real-IP": "198.51.100.8"})
+	if c.ClientIP() != "198.51.100.8" {
+		t.Errorf(expectedFormat.StringButFoundString, "198.51.100.8", c.ClientIP())
+	}
+
+	// [Test 6] Invalid hop stops the walk
+	c = resolve("10.1.2.3:1234", map[string]string{"Forwarded": "for=unknown"})
+	if c.ClientIP() != "10.1.2.3" {
+		t.Errorf(expectedFormat.StringButFoundString, "10.1.2.3", c.ClientIP())
+	}
+}
@@ -0,0 +1,106 @@
+package server
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"mime"
+	"strings"
+	"sync"
+
+	"github.com/phuc0302/go-server/util"
+)
+
+// Codec describes a serialization format that can be both bound from request & output to client.
+// Codecs of the codec sub-packages (msgpack, cbor, protobuf) or third-party packages can be
+// registered with RegisterCodec.
+type Codec interface {
+	// MediaType returns the media type that codec handles, e.g. "application/msgpack".
+	MediaType() string
+
+	// Marshal encodes model into response's body.
+	Marshal(model interface{}) ([]byte, error)
+
+	// Unmarshal decodes request's body into model.
+	Unmarshal(data []byte, model interface{}) error
+}
+
+// Decoder defines type alias for func that decodes request's body into model.
+type Decoder func(data []byte, model interface{}) error
+
+var (
+	decoders = map[string]Decoder{
+		"application/json": json.Unmarshal,
+		"application/xml":  xml.Unmarshal,
+		"text/xml":         xml.Unmarshal,
+	}
+	decodersMutex sync.RWMutex
+)
+
+// RegisterCodec makes codec available to Bind by Content-Type & to Output by Accept.
+//
+// @param
+// - codec {Codec} (the codec)
+func RegisterCodec(codec Codec) {
+	/* Condition validation: validate codec */
+	if codec == nil {
+		panic("Codec must not be nil.")
+	}
+	mediaType := strings.ToLower(codec.MediaType())
+
+	decodersMutex.Lock()
+	decoders[mediaType] = codec.Unmarshal
+	decodersMutex.Unlock()
+
+	RegisterEncoder(mediaType, codec.Marshal)
+}
+
+// Bind converts request's body to object using the codec that matches Content-Type. Structured
+// syntax suffixes, e.g. "application/vnd.api+json", fall back to their base format & forms are
+// bound by BindForm. Returned error will be a 415 status error if no codec matches.
+//
+// @param
+// - model {interface} (an associated object model)
+//
+// @return
+// - fingerprint {string} (a hex string represents data's hash)
+// - err {error} (error message during parse process)
+func (c *RequestContext) Bind(model interface{}) (fingerprint string, err error) {
+	mediaType, _, err := mime.ParseMediaType(c.request.Header.Get("Content-Type"))
+	if err != nil {
+		return "", util.Status415().AsError()
+	}
+
+	/* Condition validation: forms had been parsed by CreateContext */
+	if mediaType == "application/x-www-form-urlencoded" || mediaType == "multipart/form-data" {
+		return "", c.BindForm(model)
+	}
+
+	decode := findDecoder(mediaType)
+	if decode == nil {
+		return "", util.Status415().AsError()
+	}
+
+	/* Condition validation: validate read process */
+	bytes, err := c.readBody()
+	if err != nil {
+		return
+	}
+
+	fingerprint = generateFingerprint(bytes)
+	err = decode(bytes, model)
+	return
+}
+
+// findDecoder returns decoder of media type or its structured syntax suffix.
+func findDecoder(mediaType string) Decoder {
+	decodersMutex.RLock()
+	defer decodersMutex.RUnlock()
+
+	if decode, ok := decoders[mediaType]; ok {
+		return decode
+	}
+	if idx := strings.LastIndex(mediaType, "+"); idx >= 0 {
+		return decoders["application/"+mediaType[idx+1:]]
+	}
+	return nil
+}
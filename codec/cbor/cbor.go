@@ -0,0 +1,447 @@
+// Package cbor implements CBOR (RFC 8949) codec for server's content negotiation.
+//
+// Example:
+// server.RegisterCodec(cbor.Codec{})
+//
+// The codec is opt-in, server does not import this package unless it is registered.
+//
+// Struct fields are named by "cbor" tag, json tag or field's name. Tags are skipped while decoding,
+// integers are decoded as int64 into interface{}, unless they only fit into uint64.
+package cbor
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/phuc0302/go-server/codec/internal/generic"
+)
+
+// MediaType is CBOR's media type.
+const MediaType = "application/cbor"
+
+// maxDepth limits nested arrays & maps while decoding.
+const maxDepth = 256
+
+// Major types.
+const (
+	majorUint   = 0
+	majorNegInt = 1
+	majorBytes  = 2
+	majorText   = 3
+	majorArray  = 4
+	majorMap    = 5
+	majorTag    = 6
+	majorSimple = 7
+)
+
+// indefinite is the additional information of indefinite length items.
+const indefinite = 31
+
+// Codec describes CBOR codec.
+type Codec struct{}
+
+// MediaType returns CBOR's media type.
+func (Codec) MediaType() string {
+	return MediaType
+}
+
+// Marshal encodes model as CBOR.
+func (Codec) Marshal(model interface{}) ([]byte, error) {
+	return Marshal(model)
+}
+
+// Unmarshal decodes CBOR data into model.
+func (Codec) Unmarshal(data []byte, model interface{}) error {
+	return Unmarshal(data, model)
+}
+
+// Marshal encodes value as CBOR.
+//
+// @param
+// - value {interface} (the value that will be encoded)
+//
+// @return
+// - data {[]byte} (the encoded data)
+// - err {error} (error during encode process)
+func Marshal(value interface{}) ([]byte, error) {
+	tree, err := generic.Normalize(value, "cbor")
+	if err != nil {
+		return nil, err
+	}
+	return encode(nil, tree)
+}
+
+// Unmarshal decodes CBOR data into value, value must be a non-nil pointer.
+//
+// @param
+// - data {[]byte} (the encoded data)
+// - value {interface} (the pointer to destination)
+//
+// @return
+// - err {error} (error during decode process)
+func Unmarshal(data []byte, value interface{}) error {
+	d := &decoder{data: data}
+	tree, err := d.decode(0)
+	if err != nil {
+		return err
+	}
+	if d.offset != len(data) {
+		return fmt.Errorf("cbor: unexpected data after top-level value")
+	}
+	return generic.Assign(tree, value, "cbor")
+}
+
+// encode appends generic tree to buffer.
+func encode(buffer []byte, tree interface{}) ([]byte, error) {
+	switch value := tree.(type) {
+
+	case nil:
+		return append(buffer, 0xf6), nil
+
+	case bool:
+		if value {
+			return append(buffer, 0xf5), nil
+		}
+		return append(buffer, 0xf4), nil
+
+	case int64:
+		if value >= 0 {
+			return encodeHead(buffer, majorUint, uint64(value)), nil
+		}
+		return encodeHead(buffer, majorNegInt, uint64(-1-value)), nil
+
+	case uint64:
+		return encodeHead(buffer, majorUint, value), nil
+
+	case float32:
+		return binary.BigEndian.AppendUint32(append(buffer, 0xfa), math.Float32bits(value)), nil
+
+	case float64:
+		return binary.BigEndian.AppendUint64(append(buffer, 0xfb), math.Float64bits(value)), nil
+
+	case string:
+		return append(encodeHead(buffer, majorText, uint64(len(value))), value...), nil
+
+	case []byte:
+		return append(encodeHead(buffer, majorBytes, uint64(len(value))), value...), nil
+
+	case []interface{}:
+		buffer = encodeHead(buffer, majorArray, uint64(len(value)))
+		var err error
+		for _, item := range value {
+			if buffer, err = encode(buffer, item); err != nil {
+				return nil, err
+			}
+		}
+		return buffer, nil
+
+	case generic.Map:
+		buffer = encodeHead(buffer, majorMap, uint64(len(value)))
+		var err error
+		for _, entry := range value {
+			if buffer, err = encode(buffer, entry.Key); err != nil {
+				return nil, err
+			}
+			if buffer, err = encode(buffer, entry.Value); err != nil {
+				return nil, err
+			}
+		}
+		return buffer, nil
+	}
+	return nil, fmt.Errorf("cbor: unexpected generic value %T", tree)
+}
+
+// encodeHead appends item's head in its shortest form.
+func encodeHead(buffer []byte, major byte, argument uint64) []byte {
+	major <<= 5
+	switch {
+	case argument < 24:
+		return append(buffer, major|byte(argument))
+	case argument <= math.MaxUint8:
+		return append(buffer, major|24, byte(argument))
+	case argument <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(buffer, major|25), uint16(argument))
+	case argument <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(buffer, major|26), uint32(argument))
+	default:
+		return binary.BigEndian.AppendUint64(append(buffer, major|27), argument)
+	}
+}
+
+// decoder describes CBOR decoder's state.
+type decoder struct {
+	data   []byte
+	offset int
+}
+
+// decode reads the next item as generic tree.
+func (d *decoder) decode(depth int) (interface{}, error) {
+	if depth > maxDepth {
+		return nil, fmt.Errorf("cbor: exceeded max depth")
+	}
+
+	b, err := d.readByte()
+	if err != nil {
+		return nil, err
+	}
+	major, info := b>>5, b&0x1f
+
+	// Indefinite length items
+	if info == indefinite {
+		switch major {
+		case majorBytes, majorText:
+			return d.readChunks(major)
+		case majorArray:
+			return d.readIndefiniteArray(depth)
+		case majorMap:
+			return d.readIndefiniteMap(depth)
+		case majorSimple:
+			return nil, fmt.Errorf("cbor: unexpected break")
+		}
+		return nil, fmt.Errorf("cbor: invalid indefinite length item")
+	}
+
+	// Simple values & floats use additional information as their own format
+	if major == majorSimple {
+		return d.readSimple(info)
+	}
+
+	argument, err := d.readArgument(info)
+	if err != nil {
+		return nil, err
+	}
+
+	switch major {
+
+	case majorUint:
+		if argument > math.MaxInt64 {
+			return argument, nil
+		}
+		return int64(argument), nil
+
+	case majorNegInt:
+		if argument > math.MaxInt64 {
+			return nil, fmt.Errorf("cbor: negative integer overflows int64")
+		}
+		return -1 - int64(argument), nil
+
+	case majorBytes:
+		bytes, err := d.read(argument)
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte(nil), bytes...), nil
+
+	case majorText:
+		bytes, err := d.read(argument)
+		if err != nil {
+			return nil, err
+		}
+		return string(bytes), nil
+
+	case majorArray:
+		/* Condition validation: every item takes at least one byte */
+		if argument > uint64(len(d.data)-d.offset) {
+			return nil, fmt.Errorf("cbor: unexpected end of data")
+		}
+
+		list := make([]interface{}, argument)
+		for idx := range list {
+			if list[idx], err = d.decode(depth + 1); err != nil {
+				return nil, err
+			}
+		}
+		return list, nil
+
+	case majorMap:
+		/* Condition validation: every entry takes at least two bytes */
+		if argument > uint64(len(d.data)-d.offset)/2 {
+			return nil, fmt.Errorf("cbor: unexpected end of data")
+		}
+
+		entries := make(generic.Map, argument)
+		for idx := range entries {
+			if entries[idx], err = d.readEntry(depth); err != nil {
+				return nil, err
+			}
+		}
+		return entries, nil
+
+	default:
+		// Tags are skipped, their content is returned as it is
+		return d.decode(depth + 1)
+	}
+}
+
+// readSimple reads simple value or float.
+func (d *decoder) readSimple(info byte) (interface{}, error) {
+	switch info {
+	case 20:
+		return false, nil
+	case 21:
+		return true, nil
+	case 22, 23:
+		return nil, nil
+
+	case 25:
+		bytes, err := d.read(2)
+		if err != nil {
+			return nil, err
+		}
+		return float64(float16(binary.BigEndian.Uint16(bytes))), nil
+	case 26:
+		bytes, err := d.read(4)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float32frombits(binary.BigEndian.Uint32(bytes)), nil
+	case 27:
+		bytes, err := d.read(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(bytes)), nil
+	}
+	return nil, fmt.Errorf("cbor: unsupported simple value %d", info)
+}
+
+// readEntry reads map's entry.
+func (d *decoder) readEntry(depth int) (generic.Entry, error) {
+	key, err := d.decode(depth + 1)
+	if err != nil {
+		return generic.Entry{}, err
+	}
+	value, err := d.decode(depth + 1)
+	if err != nil {
+		return generic.Entry{}, err
+	}
+	return generic.Entry{Key: key, Value: value}, nil
+}
+
+// readChunks reads indefinite length byte or text string.
+func (d *decoder) readChunks(major byte) (interface{}, error) {
+	var buffer []byte
+	for !d.isBreak() {
+		b, err := d.readByte()
+		if err != nil {
+			return nil, err
+		}
+		if b>>5 != major || b&0x1f == indefinite {
+			return nil, fmt.Errorf("cbor: invalid chunk")
+		}
+
+		length, err := d.readArgument(b & 0x1f)
+		if err != nil {
+			return nil, err
+		}
+		chunk, err := d.read(length)
+		if err != nil {
+			return nil, err
+		}
+		buffer = append(buffer, chunk...)
+	}
+
+	if major == majorText {
+		return string(buffer), nil
+	}
+	return buffer, nil
+}
+
+// readIndefiniteArray reads array's items until break.
+func (d *decoder) readIndefiniteArray(depth int) (interface{}, error) {
+	list := make([]interface{}, 0)
+	for !d.isBreak() {
+		item, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, item)
+	}
+	return list, nil
+}
+
+// readIndefiniteMap reads map's entries until break.
+func (d *decoder) readIndefiniteMap(depth int) (interface{}, error) {
+	entries := make(generic.Map, 0)
+	for !d.isBreak() {
+		entry, err := d.readEntry(depth)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// isBreak consumes break code if it is the next byte.
+func (d *decoder) isBreak() bool {
+	if d.offset < len(d.data) && d.data[d.offset] == 0xff {
+		d.offset++
+		return true
+	}
+	return false
+}
+
+// readArgument reads head's argument.
+func (d *decoder) readArgument(info byte) (uint64, error) {
+	if info < 24 {
+		return uint64(info), nil
+	}
+	if info > 27 {
+		return 0, fmt.Errorf("cbor: invalid additional information %d", info)
+	}
+
+	bytes, err := d.read(1 << (info - 24))
+	if err != nil {
+		return 0, err
+	}
+
+	var argument uint64
+	for _, b := range bytes {
+		argument = argument<<8 | uint64(b)
+	}
+	return argument, nil
+}
+
+// readByte reads the next byte.
+func (d *decoder) readByte() (byte, error) {
+	bytes, err := d.read(1)
+	if err != nil {
+		return 0, err
+	}
+	return bytes[0], nil
+}
+
+// read reads the next length bytes.
+func (d *decoder) read(length uint64) ([]byte, error) {
+	if length > uint64(len(d.data)-d.offset) {
+		return nil, fmt.Errorf("cbor: unexpected end of data")
+	}
+
+	bytes := d.data[d.offset : d.offset+int(length)]
+	d.offset += int(length)
+	return bytes, nil
+}
+
+// float16 converts IEEE 754 half precision bits into float32.
+func float16(bits uint16) float32 {
+	sign := uint32(bits>>15) << 31
+	exponent := uint32(bits>>10) & 0x1f
+	mantissa := uint32(bits) & 0x3ff
+
+	switch exponent {
+	case 0:
+		// Zero & subnormal numbers
+		value := float32(mantissa) / (1 << 24)
+		if sign != 0 {
+			return -value
+		}
+		return value
+	case 0x1f:
+		// Infinity & NaN
+		return math.Float32frombits(sign | 0x7f800000 | mantissa<<13)
+	default:
+		return math.Float32frombits(sign | (exponent+112)<<23 | mantissa<<13)
+	}
+}
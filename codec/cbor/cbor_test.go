@@ -0,0 +1,148 @@
+package cbor
+
+import (
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/phuc0302/go-server/expected_format"
+)
+
+func Test_Marshal(t *testing.T) {
+	// Examples from RFC 8949 appendix A
+	tests := []struct {
+		value    interface{}
+		expected string
+	}{
+		{0, "00"},
+		{24, "1818"},
+		{-1, "20"},
+		{-1000, "3903e7"},
+		{uint64(1000000000000), "1b000000e8d4a51000"},
+		{1.1, "fb3ff199999999999a"},
+		{false, "f4"},
+		{nil, "f6"},
+		{"a", "6161"},
+		{[]byte{1, 2, 3, 4}, "4401020304"},
+		{[]interface{}{1, []int{2, 3}}, "8201820203"},
+		{map[string]int{"a": 1}, "a1616101"},
+	}
+
+	for _, test := range tests {
+		if data, err := Marshal(test.value); err != nil || hex.EncodeToString(data) != test.expected {
+			t.Errorf(expectedFormat.StringButFoundString, test.expected, hex.EncodeToString(data))
+		}
+	}
+}
+
+func Test_Unmarshal(t *testing.T) {
+	// Examples from RFC 8949 appendix A
+	tests := []struct {
+		data     string
+		expected interface{}
+	}{
+		{"f93e00", 1.5},
+		{"f90400", 0.00006103515625},
+		{"c11a514b67b0", int64(1363896240)},
+		{"7f657374726561646d696e67ff", "streaming"},
+		{"9f018202039f0405ffff", []interface{}{int64(1), []interface{}{int64(2), int64(3)}, []interface{}{int64(4), int64(5)}}},
+		{"bf61610161629f0203ffff", map[string]interface{}{"a": int64(1), "b": []interface{}{int64(2), int64(3)}}},
+	}
+
+	for _, test := range tests {
+		data, _ := hex.DecodeString(test.data)
+
+		var value interface{}
+		if err := Unmarshal(data, &value); err != nil {
+			t.Errorf(expectedFormat.StringButFoundString, "nil", err)
+		}
+		if expected, found := hex.EncodeToString(mustMarshal(test.expected)), hex.EncodeToString(mustMarshal(value)); expected != found {
+			t.Errorf(expectedFormat.StringButFoundString, expected, found)
+		}
+	}
+}
+
+func Test_RoundTrip(t *testing.T) {
+	type sample struct {
+		Name      string    `cbor:"name"`
+		Balance   int32     `json:"balance"`
+		Tags      []string  `cbor:"tags"`
+		CreatedAt time.Time `cbor:"created_at"`
+	}
+	input := sample{Name: "sample", Balance: -42, Tags: []string{"a"}, CreatedAt: time.Date(2016, time.October, 1, 8, 0, 0, 0, time.UTC)}
+
+	var output sample
+	if err := Unmarshal(mustMarshal(input), &output); err != nil {
+		t.Errorf(expectedFormat.StringButFoundString, "nil", err)
+	}
+	if output.Name != input.Name || output.Balance != input.Balance || len(output.Tags) != 1 || !output.CreatedAt.Equal(input.CreatedAt) {
+		t.Errorf(expectedFormat.StringButFoundString, input.Name, output.Name)
+	}
+
+	// Truncated data must fail
+	data := mustMarshal(input)
+	if err := Unmarshal(data[:len(data)-1], &output); err == nil {
+		t.Error(expectedFormat.NotNil)
+	}
+}
+
+func mustMarshal(value interface{}) []byte {
+	data, err := Marshal(value)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+type embedded struct {
+	Name string `cbor:"name"`
+}
+
+type outer struct {
+	*embedded
+	Count int `cbor:"count"`
+}
+
+func Test_Unmarshal_Regressions(t *testing.T) {
+	// [Test 1] Embedded pointer to unexported struct is skipped
+	var output outer
+	if err := Unmarshal(mustMarshal(map[string]interface{}{"name": "a", "count": 1}), &output); err != nil {
+		t.Errorf(expectedFormat.StringButFoundString, "nil", err)
+	}
+	if output.embedded != nil || output.Count != 1 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 1, output.Count)
+	}
+
+	// [Test 2] Unhashable key, e.g. {[1]: 2}
+	var m map[interface{}]int
+	if err := Unmarshal([]byte{0xa1, 0x81, 0x01, 0x02}, &m); err == nil {
+		t.Error(expectedFormat.NotNil)
+	}
+}
+
+func Test_Encode_UnexpectedValue(t *testing.T) {
+	if _, err := encode(nil, struct{}{}); err == nil {
+		t.Error(expectedFormat.NotNil)
+	}
+}
+
+func FuzzUnmarshal(f *testing.F) {
+	f.Add([]byte{0xa1, 0x61, 0x61, 0x01})
+	f.Add([]byte{0xa1, 0x81, 0x01, 0x02})
+	f.Add([]byte{0x9f, 0x01, 0x82, 0x02, 0x03, 0x9f, 0x04, 0x05, 0xff, 0xff})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var value interface{}
+		if err := Unmarshal(data, &value); err == nil {
+			if _, err := Marshal(value); err != nil {
+				t.Errorf(expectedFormat.StringButFoundString, "nil", err)
+			}
+		}
+
+		var output outer
+		Unmarshal(data, &output)
+
+		var m map[interface{}]interface{}
+		Unmarshal(data, &m)
+	})
+}
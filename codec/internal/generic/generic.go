@@ -0,0 +1,534 @@
+// Package generic converts Go values to & from a generic tree that binary codecs can serialize.
+//
+// The tree is made of nil, bool, int64, uint64, float32, float64, string, []byte, []interface{} and
+// Map. Struct fields are named by codec's tag, json tag or field's name.
+package generic
+
+import (
+	"encoding"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Entry describes a map's entry.
+type Entry struct {
+	Key   interface{}
+	Value interface{}
+}
+
+// Map describes a map that keeps its entries' order.
+type Map []Entry
+
+var (
+	textMarshaler   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshaler = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// Normalize converts value into generic tree.
+//
+// @param
+// - value {interface} (the value that will be converted)
+// - tag {string} (the struct tag that names fields, e.g. "msgpack")
+//
+// @return
+// - tree {interface} (the generic tree)
+// - err {error} (error during convert process)
+func Normalize(value interface{}, tag string) (interface{}, error) {
+	return normalize(reflect.ValueOf(value), tag)
+}
+
+// normalize converts reflected value into generic tree.
+func normalize(v reflect.Value, tag string) (interface{}, error) {
+	if !v.IsValid() {
+		return nil, nil
+	}
+
+	// Values that know how to present themselves, e.g. time.Time
+	if v.Type().Implements(textMarshaler) && (v.Kind() != reflect.Ptr || !v.IsNil()) {
+		text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return nil, err
+		}
+		return string(text), nil
+	}
+
+	switch v.Kind() {
+
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil, nil
+		}
+		return normalize(v.Elem(), tag)
+
+	case reflect.Bool:
+		return v.Bool(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint(), nil
+	case reflect.Float32:
+		return float32(v.Float()), nil
+	case reflect.Float64:
+		return v.Float(), nil
+	case reflect.String:
+		return v.String(), nil
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil, nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return v.Bytes(), nil
+		}
+		return normalizeList(v, tag)
+
+	case reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			bytes := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(bytes), v)
+			return bytes, nil
+		}
+		return normalizeList(v, tag)
+
+	case reflect.Map:
+		if v.IsNil() {
+			return nil, nil
+		}
+
+		entries := make(Map, 0, v.Len())
+		iterator := v.MapRange()
+		for iterator.Next() {
+			key, err := normalize(iterator.Key(), tag)
+			if err != nil {
+				return nil, err
+			}
+			value, err := normalize(iterator.Value(), tag)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, Entry{Key: key, Value: value})
+		}
+
+		// Sort entries so that output is deterministic
+		sort.Slice(entries, func(i, j int) bool {
+			return fmt.Sprint(entries[i].Key) < fmt.Sprint(entries[j].Key)
+		})
+		return entries, nil
+
+	case reflect.Struct:
+		fields := cachedFields(v.Type(), tag)
+		entries := make(Map, 0, len(fields))
+		for _, f := range fields {
+			field, ok := fieldByIndex(v, f.index)
+			if !ok || (f.omitEmpty && field.IsZero()) {
+				continue
+			}
+
+			value, err := normalize(field, tag)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, Entry{Key: f.name, Value: value})
+		}
+		return entries, nil
+
+	default:
+		return nil, fmt.Errorf("generic: unsupported type %s", v.Type())
+	}
+}
+
+// normalizeList converts slice or array into generic list.
+func normalizeList(v reflect.Value, tag string) (interface{}, error) {
+	list := make([]interface{}, v.Len())
+	for idx := range list {
+		item, err := normalize(v.Index(idx), tag)
+		if err != nil {
+			return nil, err
+		}
+		list[idx] = item
+	}
+	return list, nil
+}
+
+// Assign stores generic tree into target, target must be a non-nil pointer.
+//
+// @param
+// - tree {interface} (the generic tree)
+// - target {interface} (the pointer to destination)
+// - tag {string} (the struct tag that names fields, e.g. "msgpack")
+//
+// @return
+// - err {error} (error during assign process)
+func Assign(tree interface{}, target interface{}, tag string) error {
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return fmt.Errorf("generic: target must be a non-nil pointer")
+	}
+	return assign(tree, v.Elem(), tag)
+}
+
+// assign stores generic tree into reflected value.
+func assign(src interface{}, dst reflect.Value, tag string) error {
+	if src == nil {
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+
+	// Allocate pointer if necessary
+	if dst.Kind() == reflect.Ptr {
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		return assign(src, dst.Elem(), tag)
+	}
+
+	// Values that know how to parse themselves, e.g. time.Time
+	if text, ok := src.(string); ok && dst.CanAddr() && dst.Addr().Type().Implements(textUnmarshaler) {
+		return dst.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(text))
+	}
+
+	switch dst.Kind() {
+
+	case reflect.Interface:
+		if dst.NumMethod() != 0 {
+			break
+		}
+		if plain := Plain(src); plain != nil {
+			dst.Set(reflect.ValueOf(plain))
+		}
+		return nil
+
+	case reflect.Bool:
+		if b, ok := src.(bool); ok {
+			dst.SetBool(b)
+			return nil
+		}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if integer, ok := toInt(src); ok && !dst.OverflowInt(integer) {
+			dst.SetInt(integer)
+			return nil
+		}
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if unsignInteger, ok := toUint(src); ok && !dst.OverflowUint(unsignInteger) {
+			dst.SetUint(unsignInteger)
+			return nil
+		}
+
+	case reflect.Float32, reflect.Float64:
+		if f, ok := toFloat(src); ok {
+			dst.SetFloat(f)
+			return nil
+		}
+
+	case reflect.String:
+		switch value := src.(type) {
+		case string:
+			dst.SetString(value)
+			return nil
+		case []byte:
+			dst.SetString(string(value))
+			return nil
+		}
+
+	case reflect.Slice:
+		if dst.Type().Elem().Kind() == reflect.Uint8 {
+			switch value := src.(type) {
+			case []byte:
+				dst.SetBytes(append([]byte(nil), value...))
+				return nil
+			case string:
+				dst.SetBytes([]byte(value))
+				return nil
+			}
+		}
+		if list, ok := src.([]interface{}); ok {
+			slice := reflect.MakeSlice(dst.Type(), len(list), len(list))
+			for idx, item := range list {
+				if err := assign(item, slice.Index(idx), tag); err != nil {
+					return err
+				}
+			}
+			dst.Set(slice)
+			return nil
+		}
+
+	case reflect.Array:
+		if bytes, ok := src.([]byte); ok && dst.Type().Elem().Kind() == reflect.Uint8 {
+			reflect.Copy(dst, reflect.ValueOf(bytes))
+			return nil
+		}
+		if list, ok := src.([]interface{}); ok {
+			for idx := 0; idx < dst.Len() && idx < len(list); idx++ {
+				if err := assign(list[idx], dst.Index(idx), tag); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+	case reflect.Map:
+		if entries, ok := src.(Map); ok {
+			if dst.IsNil() {
+				dst.Set(reflect.MakeMapWithSize(dst.Type(), len(entries)))
+			}
+			for _, entry := range entries {
+				key := reflect.New(dst.Type().Key()).Elem()
+				if err := assign(entry.Key, key, tag); err != nil {
+					return err
+				}
+				if !key.Comparable() {
+					return fmt.Errorf("generic: unhashable map key %T", entry.Key)
+				}
+				value := reflect.New(dst.Type().Elem()).Elem()
+				if err := assign(entry.Value, value, tag); err != nil {
+					return err
+				}
+				dst.SetMapIndex(key, value)
+			}
+			return nil
+		}
+
+	case reflect.Struct:
+		if entries, ok := src.(Map); ok {
+			fields := cachedFields(dst.Type(), tag)
+			for _, entry := range entries {
+				name, ok := entry.Key.(string)
+				if !ok {
+					continue
+				}
+				f := findField(fields, name)
+				if f == nil {
+					continue
+				}
+
+				// Like encoding/json, skip field of nil embedded pointer to unexported struct
+				if field, ok := allocateField(dst, f.index); ok {
+					if err := assign(entry.Value, field, tag); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("generic: cannot assign %T to %s", src, dst.Type())
+}
+
+// Plain converts generic tree into plain Go values: maps with string keys become
+// map[string]interface{}, other maps become map[interface{}]interface{}.
+func Plain(tree interface{}) interface{} {
+	switch value := tree.(type) {
+
+	case []interface{}:
+		list := make([]interface{}, len(value))
+		for idx, item := range value {
+			list[idx] = Plain(item)
+		}
+		return list
+
+	case Map:
+		stringKeys := true
+		for _, entry := range value {
+			if _, ok := entry.Key.(string); !ok {
+				stringKeys = false
+				break
+			}
+		}
+
+		if stringKeys {
+			m := make(map[string]interface{}, len(value))
+			for _, entry := range value {
+				m[entry.Key.(string)] = Plain(entry.Value)
+			}
+			return m
+		}
+
+		m := make(map[interface{}]interface{}, len(value))
+		for _, entry := range value {
+			key := Plain(entry.Key)
+			if key != nil && !reflect.TypeOf(key).Comparable() {
+				key = fmt.Sprint(key)
+			}
+			m[key] = Plain(entry.Value)
+		}
+		return m
+
+	default:
+		return tree
+	}
+}
+
+// toInt converts generic number into int64.
+func toInt(src interface{}) (int64, bool) {
+	switch value := src.(type) {
+	case int64:
+		return value, true
+	case uint64:
+		return int64(value), value <= math.MaxInt64
+	case float32:
+		return int64(value), float32(int64(value)) == value
+	case float64:
+		return int64(value), float64(int64(value)) == value
+	}
+	return 0, false
+}
+
+// toUint converts generic number into uint64.
+func toUint(src interface{}) (uint64, bool) {
+	switch value := src.(type) {
+	case int64:
+		return uint64(value), value >= 0
+	case uint64:
+		return value, true
+	case float32:
+		return uint64(value), value >= 0 && float32(uint64(value)) == value
+	case float64:
+		return uint64(value), value >= 0 && float64(uint64(value)) == value
+	}
+	return 0, false
+}
+
+// toFloat converts generic number into float64.
+func toFloat(src interface{}) (float64, bool) {
+	switch value := src.(type) {
+	case int64:
+		return float64(value), true
+	case uint64:
+		return float64(value), true
+	case float32:
+		return float64(value), true
+	case float64:
+		return value, true
+	}
+	return 0, false
+}
+
+// field describes a struct's field.
+type field struct {
+	name      string
+	index     []int
+	omitEmpty bool
+}
+
+// fieldsKey identifies cached fields.
+type fieldsKey struct {
+	t   reflect.Type
+	tag string
+}
+
+var fieldsCache sync.Map
+
+// cachedFields returns struct's fields, fields of embedded structs are promoted.
+func cachedFields(t reflect.Type, tag string) []field {
+	key := fieldsKey{t: t, tag: tag}
+	if fields, ok := fieldsCache.Load(key); ok {
+		return fields.([]field)
+	}
+
+	fields := structFields(t, tag, nil)
+	fieldsCache.Store(key, fields)
+	return fields
+}
+
+// structFields collects struct's fields.
+func structFields(t reflect.Type, tag string, index []int) []field {
+	var fields []field
+	for idx := 0; idx < t.NumField(); idx++ {
+		structField := t.Field(idx)
+		fieldIndex := append(append([]int(nil), index...), idx)
+
+		name, options := parseTag(structField, tag)
+		if name == "-" {
+			continue
+		}
+
+		// Promote fields of embedded struct
+		fieldType := structField.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if structField.Anonymous && len(name) == 0 && fieldType.Kind() == reflect.Struct {
+			fields = append(fields, structFields(fieldType, tag, fieldIndex)...)
+			continue
+		}
+		if len(structField.PkgPath) > 0 {
+			continue
+		}
+
+		if len(name) == 0 {
+			name = structField.Name
+		}
+		fields = append(fields, field{
+			name:      name,
+			index:     fieldIndex,
+			omitEmpty: strings.Contains(options, "omitempty"),
+		})
+	}
+	return fields
+}
+
+// parseTag returns field's name & options from codec's tag or json tag.
+func parseTag(structField reflect.StructField, tag string) (string, string) {
+	value, ok := structField.Tag.Lookup(tag)
+	if !ok {
+		value = structField.Tag.Get("json")
+	}
+
+	if idx := strings.Index(value, ","); idx >= 0 {
+		return value[:idx], value[idx+1:]
+	}
+	return value, ""
+}
+
+// findField finds field by name, case-insensitive match is used as fallback.
+func findField(fields []field, name string) *field {
+	for idx := range fields {
+		if fields[idx].name == name {
+			return &fields[idx]
+		}
+	}
+	for idx := range fields {
+		if strings.EqualFold(fields[idx].name, name) {
+			return &fields[idx]
+		}
+	}
+	return nil
+}
+
+// fieldByIndex returns nested field, ok will be false if an embedded pointer is nil.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for _, idx := range index {
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(idx)
+	}
+	return v, true
+}
+
+// allocateField returns nested field, embedded pointers will be allocated. ok will be false if an
+// embedded pointer is nil & cannot be allocated, e.g. pointer to unexported struct.
+func allocateField(v reflect.Value, index []int) (reflect.Value, bool) {
+	for _, idx := range index {
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}, false
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(idx)
+	}
+	return v, true
+}
@@ -0,0 +1,407 @@
+// Package msgpack implements MessagePack codec for server's content negotiation.
+//
+// Example:
+// server.RegisterCodec(msgpack.Codec{})
+//
+// The codec is opt-in, server does not import this package unless it is registered.
+//
+// Struct fields are named by "msgpack" tag, json tag or field's name. Integers are decoded as int64
+// into interface{}, unless they only fit into uint64.
+package msgpack
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/phuc0302/go-server/codec/internal/generic"
+)
+
+// MediaType is MessagePack's media type.
+const MediaType = "application/msgpack"
+
+// maxDepth limits nested arrays & maps while decoding.
+const maxDepth = 256
+
+// Codec describes MessagePack codec.
+type Codec struct{}
+
+// MediaType returns MessagePack's media type.
+func (Codec) MediaType() string {
+	return MediaType
+}
+
+// Marshal encodes model as MessagePack.
+func (Codec) Marshal(model interface{}) ([]byte, error) {
+	return Marshal(model)
+}
+
+// Unmarshal decodes MessagePack data into model.
+func (Codec) Unmarshal(data []byte, model interface{}) error {
+	return Unmarshal(data, model)
+}
+
+// Marshal encodes value as MessagePack.
+//
+// @param
+// - value {interface} (the value that will be encoded)
+//
+// @return
+// - data {[]byte} (the encoded data)
+// - err {error} (error during encode process)
+func Marshal(value interface{}) ([]byte, error) {
+	tree, err := generic.Normalize(value, "msgpack")
+	if err != nil {
+		return nil, err
+	}
+	return encode(nil, tree)
+}
+
+// Unmarshal decodes MessagePack data into value, value must be a non-nil pointer.
+//
+// @param
+// - data {[]byte} (the encoded data)
+// - value {interface} (the pointer to destination)
+//
+// @return
+// - err {error} (error during decode process)
+func Unmarshal(data []byte, value interface{}) error {
+	d := &decoder{data: data}
+	tree, err := d.decode(0)
+	if err != nil {
+		return err
+	}
+	if d.offset != len(data) {
+		return fmt.Errorf("msgpack: unexpected data after top-level value")
+	}
+	return generic.Assign(tree, value, "msgpack")
+}
+
+// encode appends generic tree to buffer.
+func encode(buffer []byte, tree interface{}) ([]byte, error) {
+	switch value := tree.(type) {
+
+	case nil:
+		return append(buffer, 0xc0), nil
+
+	case bool:
+		if value {
+			return append(buffer, 0xc3), nil
+		}
+		return append(buffer, 0xc2), nil
+
+	case int64:
+		if value >= 0 {
+			return encodeUint(buffer, uint64(value)), nil
+		}
+		switch {
+		case value >= -32:
+			return append(buffer, byte(value)), nil
+		case value >= math.MinInt8:
+			return append(buffer, 0xd0, byte(value)), nil
+		case value >= math.MinInt16:
+			return binary.BigEndian.AppendUint16(append(buffer, 0xd1), uint16(value)), nil
+		case value >= math.MinInt32:
+			return binary.BigEndian.AppendUint32(append(buffer, 0xd2), uint32(value)), nil
+		default:
+			return binary.BigEndian.AppendUint64(append(buffer, 0xd3), uint64(value)), nil
+		}
+
+	case uint64:
+		return encodeUint(buffer, value), nil
+
+	case float32:
+		return binary.BigEndian.AppendUint32(append(buffer, 0xca), math.Float32bits(value)), nil
+
+	case float64:
+		return binary.BigEndian.AppendUint64(append(buffer, 0xcb), math.Float64bits(value)), nil
+
+	case string:
+		length := len(value)
+		if uint64(length) > math.MaxUint32 {
+			return nil, fmt.Errorf("msgpack: %T is too large", value)
+		}
+		switch {
+		case length < 32:
+			buffer = append(buffer, 0xa0|byte(length))
+		case length <= math.MaxUint8:
+			buffer = append(buffer, 0xd9, byte(length))
+		case length <= math.MaxUint16:
+			buffer = binary.BigEndian.AppendUint16(append(buffer, 0xda), uint16(length))
+		default:
+			buffer = binary.BigEndian.AppendUint32(append(buffer, 0xdb), uint32(length))
+		}
+		return append(buffer, value...), nil
+
+	case []byte:
+		length := len(value)
+		if uint64(length) > math.MaxUint32 {
+			return nil, fmt.Errorf("msgpack: %T is too large", value)
+		}
+		switch {
+		case length <= math.MaxUint8:
+			buffer = append(buffer, 0xc4, byte(length))
+		case length <= math.MaxUint16:
+			buffer = binary.BigEndian.AppendUint16(append(buffer, 0xc5), uint16(length))
+		default:
+			buffer = binary.BigEndian.AppendUint32(append(buffer, 0xc6), uint32(length))
+		}
+		return append(buffer, value...), nil
+
+	case []interface{}:
+		length := len(value)
+		if uint64(length) > math.MaxUint32 {
+			return nil, fmt.Errorf("msgpack: %T is too large", value)
+		}
+		switch {
+		case length < 16:
+			buffer = append(buffer, 0x90|byte(length))
+		case length <= math.MaxUint16:
+			buffer = binary.BigEndian.AppendUint16(append(buffer, 0xdc), uint16(length))
+		default:
+			buffer = binary.BigEndian.AppendUint32(append(buffer, 0xdd), uint32(length))
+		}
+		var err error
+		for _, item := range value {
+			if buffer, err = encode(buffer, item); err != nil {
+				return nil, err
+			}
+		}
+		return buffer, nil
+
+	case generic.Map:
+		length := len(value)
+		if uint64(length) > math.MaxUint32 {
+			return nil, fmt.Errorf("msgpack: %T is too large", value)
+		}
+		switch {
+		case length < 16:
+			buffer = append(buffer, 0x80|byte(length))
+		case length <= math.MaxUint16:
+			buffer = binary.BigEndian.AppendUint16(append(buffer, 0xde), uint16(length))
+		default:
+			buffer = binary.BigEndian.AppendUint32(append(buffer, 0xdf), uint32(length))
+		}
+		var err error
+		for _, entry := range value {
+			if buffer, err = encode(buffer, entry.Key); err != nil {
+				return nil, err
+			}
+			if buffer, err = encode(buffer, entry.Value); err != nil {
+				return nil, err
+			}
+		}
+		return buffer, nil
+	}
+	return nil, fmt.Errorf("msgpack: unexpected generic value %T", tree)
+}
+
+// encodeUint appends unsigned integer in its smallest form.
+func encodeUint(buffer []byte, value uint64) []byte {
+	switch {
+	case value < 128:
+		return append(buffer, byte(value))
+	case value <= math.MaxUint8:
+		return append(buffer, 0xcc, byte(value))
+	case value <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(buffer, 0xcd), uint16(value))
+	case value <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(buffer, 0xce), uint32(value))
+	default:
+		return binary.BigEndian.AppendUint64(append(buffer, 0xcf), value)
+	}
+}
+
+// decoder describes MessagePack decoder's state.
+type decoder struct {
+	data   []byte
+	offset int
+}
+
+// decode reads the next value as generic tree.
+func (d *decoder) decode(depth int) (interface{}, error) {
+	if depth > maxDepth {
+		return nil, fmt.Errorf("msgpack: exceeded max depth")
+	}
+
+	b, err := d.readByte()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case b <= 0x7f:
+		return int64(b), nil
+	case b >= 0xe0:
+		return int64(int8(b)), nil
+	case b&0xe0 == 0xa0:
+		return d.readString(int(b & 0x1f))
+	case b&0xf0 == 0x90:
+		return d.readArray(int(b&0x0f), depth)
+	case b&0xf0 == 0x80:
+		return d.readMap(int(b&0x0f), depth)
+	}
+
+	switch b {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+
+	case 0xc4, 0xc5, 0xc6:
+		length, err := d.readLength(b - 0xc4)
+		if err != nil {
+			return nil, err
+		}
+		bytes, err := d.read(length)
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte(nil), bytes...), nil
+
+	case 0xca:
+		bytes, err := d.read(4)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float32frombits(binary.BigEndian.Uint32(bytes)), nil
+	case 0xcb:
+		bytes, err := d.read(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(bytes)), nil
+
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		value, err := d.readUint(1 << (b - 0xcc))
+		if err != nil {
+			return nil, err
+		}
+		if value > math.MaxInt64 {
+			return value, nil
+		}
+		return int64(value), nil
+
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		size := 1 << (b - 0xd0)
+		value, err := d.readUint(size)
+		if err != nil {
+			return nil, err
+		}
+		shift := uint(64 - size*8)
+		return int64(value<<shift) >> shift, nil
+
+	case 0xd9, 0xda, 0xdb:
+		length, err := d.readLength(b - 0xd9)
+		if err != nil {
+			return nil, err
+		}
+		return d.readString(length)
+
+	case 0xdc, 0xdd:
+		length, err := d.readLength(b - 0xdc + 1)
+		if err != nil {
+			return nil, err
+		}
+		return d.readArray(length, depth)
+
+	case 0xde, 0xdf:
+		length, err := d.readLength(b - 0xde + 1)
+		if err != nil {
+			return nil, err
+		}
+		return d.readMap(length, depth)
+	}
+	return nil, fmt.Errorf("msgpack: unsupported type 0x%02x", b)
+}
+
+// readArray reads array's items.
+func (d *decoder) readArray(length int, depth int) (interface{}, error) {
+	/* Condition validation: every item takes at least one byte */
+	if length > len(d.data)-d.offset {
+		return nil, fmt.Errorf("msgpack: unexpected end of data")
+	}
+
+	list := make([]interface{}, length)
+	for idx := range list {
+		item, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		list[idx] = item
+	}
+	return list, nil
+}
+
+// readMap reads map's entries.
+func (d *decoder) readMap(length int, depth int) (interface{}, error) {
+	/* Condition validation: every entry takes at least two bytes */
+	if length > (len(d.data)-d.offset)/2 {
+		return nil, fmt.Errorf("msgpack: unexpected end of data")
+	}
+
+	entries := make(generic.Map, length)
+	for idx := range entries {
+		key, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		value, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		entries[idx] = generic.Entry{Key: key, Value: value}
+	}
+	return entries, nil
+}
+
+// readString reads string of length.
+func (d *decoder) readString(length int) (interface{}, error) {
+	bytes, err := d.read(length)
+	if err != nil {
+		return nil, err
+	}
+	return string(bytes), nil
+}
+
+// readLength reads 1, 2 or 4 bytes length, sizeClass is 0, 1 or 2 respectively.
+func (d *decoder) readLength(sizeClass byte) (int, error) {
+	length, err := d.readUint(1 << sizeClass)
+	return int(length), err
+}
+
+// readUint reads big endian unsigned integer of size bytes.
+func (d *decoder) readUint(size int) (uint64, error) {
+	bytes, err := d.read(size)
+	if err != nil {
+		return 0, err
+	}
+
+	var value uint64
+	for _, b := range bytes {
+		value = value<<8 | uint64(b)
+	}
+	return value, nil
+}
+
+// readByte reads the next byte.
+func (d *decoder) readByte() (byte, error) {
+	bytes, err := d.read(1)
+	if err != nil {
+		return 0, err
+	}
+	return bytes[0], nil
+}
+
+// read reads the next length bytes.
+func (d *decoder) read(length int) ([]byte, error) {
+	if length < 0 || length > len(d.data)-d.offset {
+		return nil, fmt.Errorf("msgpack: unexpected end of data")
+	}
+
+	bytes := d.data[d.offset : d.offset+length]
+	d.offset += length
+	return bytes, nil
+}
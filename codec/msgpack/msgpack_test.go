@@ -0,0 +1,156 @@
+package msgpack
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/phuc0302/go-server/expected_format"
+)
+
+type sample struct {
+	Name      string            `msgpack:"name"`
+	Count     int               `json:"count"`
+	Balance   int64             `msgpack:"balance"`
+	Ratio     float64           `msgpack:"ratio"`
+	Big       uint64            `msgpack:"big"`
+	Tags      []string          `msgpack:"tags"`
+	Data      []byte            `msgpack:"data"`
+	Labels    map[string]int    `msgpack:"labels"`
+	Parent    *sample           `msgpack:"parent,omitempty"`
+	CreatedAt time.Time         `msgpack:"created_at"`
+	Extra     interface{}       `msgpack:"extra"`
+	Ignored   string            `msgpack:"-"`
+	Headers   map[string]string `msgpack:"headers,omitempty"`
+}
+
+func Test_Marshal(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		expected []byte
+	}{
+		{nil, []byte{0xc0}},
+		{true, []byte{0xc3}},
+		{1, []byte{0x01}},
+		{-1, []byte{0xff}},
+		{-33, []byte{0xd0, 0xdf}},
+		{200, []byte{0xcc, 0xc8}},
+		{uint64(math.MaxUint64), []byte{0xcf, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+		{1.5, []byte{0xcb, 0x3f, 0xf8, 0, 0, 0, 0, 0, 0}},
+		{"a", []byte{0xa1, 'a'}},
+		{[]int{1, 2}, []byte{0x92, 0x01, 0x02}},
+		{map[string]int{"a": 1}, []byte{0x81, 0xa1, 'a', 0x01}},
+	}
+
+	for _, test := range tests {
+		if data, err := Marshal(test.value); err != nil || !bytes.Equal(data, test.expected) {
+			t.Errorf(expectedFormat.StringButFoundString, test.expected, data)
+		}
+	}
+}
+
+func Test_RoundTrip(t *testing.T) {
+	createdAt := time.Date(2016, time.October, 1, 8, 0, 0, 0, time.UTC)
+	input := sample{
+		Name:      "sample",
+		Count:     3,
+		Balance:   -70000,
+		Ratio:     0.25,
+		Big:       math.MaxUint64,
+		Tags:      []string{"a", "b"},
+		Data:      []byte{1, 2, 3},
+		Labels:    map[string]int{"x": 1},
+		Parent:    &sample{Name: "parent"},
+		CreatedAt: createdAt,
+		Extra:     map[string]interface{}{"nested": []interface{}{int64(1), "two"}},
+		Ignored:   "ignored",
+	}
+
+	data, err := Marshal(input)
+	if err != nil {
+		t.Error(expectedFormat.Nil)
+	}
+
+	var output sample
+	if err := Unmarshal(data, &output); err != nil {
+		t.Errorf(expectedFormat.StringButFoundString, "nil", err)
+	}
+
+	if output.Name != "sample" || output.Count != 3 || output.Balance != -70000 || output.Ratio != 0.25 || output.Big != math.MaxUint64 {
+		t.Errorf(expectedFormat.StringButFoundString, "sample", output.Name)
+	}
+	if len(output.Tags) != 2 || !bytes.Equal(output.Data, input.Data) || output.Labels["x"] != 1 {
+		t.Error("Expected collections had been decoded.")
+	}
+	if output.Parent == nil || output.Parent.Name != "parent" || !output.CreatedAt.Equal(createdAt) || len(output.Ignored) > 0 {
+		t.Error("Expected nested values had been decoded.")
+	}
+	if extra, ok := output.Extra.(map[string]interface{}); !ok || extra["nested"].([]interface{})[1] != "two" {
+		t.Error("Expected generic values had been decoded.")
+	}
+}
+
+func Test_Unmarshal_InvalidData(t *testing.T) {
+	var value interface{}
+
+	// Truncated string, oversized array & trailing data
+	for _, data := range [][]byte{{0xa5, 'a'}, {0xdd, 0xff, 0xff, 0xff, 0xff}, {0x01, 0x02}} {
+		if err := Unmarshal(data, &value); err == nil {
+			t.Error(expectedFormat.NotNil)
+		}
+	}
+}
+
+type embedded struct {
+	Name string `msgpack:"name"`
+}
+
+type outer struct {
+	*embedded
+	Count int `msgpack:"count"`
+}
+
+func Test_Unmarshal_Regressions(t *testing.T) {
+	// [Test 1] Embedded pointer to unexported struct is skipped
+	var output outer
+	if err := Unmarshal([]byte{0x82, 0xa4, 'n', 'a', 'm', 'e', 0xa1, 'a', 0xa5, 'c', 'o', 'u', 'n', 't', 0x01}, &output); err != nil {
+		t.Errorf(expectedFormat.StringButFoundString, "nil", err)
+	}
+	if output.embedded != nil || output.Count != 1 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 1, output.Count)
+	}
+
+	// [Test 2] Unhashable key, e.g. {[1]: 2}
+	var m map[interface{}]int
+	if err := Unmarshal([]byte{0x81, 0x91, 0x01, 0x02}, &m); err == nil {
+		t.Error(expectedFormat.NotNil)
+	}
+}
+
+func Test_Encode_UnexpectedValue(t *testing.T) {
+	if _, err := encode(nil, struct{}{}); err == nil {
+		t.Error(expectedFormat.NotNil)
+	}
+}
+
+func FuzzUnmarshal(f *testing.F) {
+	f.Add([]byte{0x81, 0xa1, 'a', 0x01})
+	f.Add([]byte{0x81, 0x91, 0x01, 0x02})
+	f.Add([]byte{0x92, 0xcb, 0x3f, 0xf8, 0, 0, 0, 0, 0, 0, 0xc4, 0x01, 0xff})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var value interface{}
+		if err := Unmarshal(data, &value); err == nil {
+			if _, err := Marshal(value); err != nil {
+				t.Errorf(expectedFormat.StringButFoundString, "nil", err)
+			}
+		}
+
+		var output sample
+		Unmarshal(data, &output)
+
+		var m map[interface{}]interface{}
+		Unmarshal(data, &m)
+	})
+}
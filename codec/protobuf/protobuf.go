@@ -0,0 +1,75 @@
+// Package protobuf adapts Protocol Buffers to server's content negotiation without depending on a
+// specific protobuf runtime.
+//
+// Messages that implement Marshal & Unmarshal methods, e.g. gogo/protobuf or vtprotobuf generated
+// types, work with the zero Codec:
+//
+//	server.RegisterCodec(protobuf.Codec{})
+//
+// Other runtimes can be plugged by functions, e.g. google.golang.org/protobuf:
+//
+//	server.RegisterCodec(protobuf.New(
+//		func(v interface{}) ([]byte, error) { return proto.Marshal(v.(proto.Message)) },
+//		func(data []byte, v interface{}) error { return proto.Unmarshal(data, v.(proto.Message)) },
+//	))
+package protobuf
+
+import "fmt"
+
+// MediaType is Protocol Buffers' media type.
+const MediaType = "application/x-protobuf"
+
+// Marshaler describes a message that encodes itself.
+type Marshaler interface {
+	Marshal() ([]byte, error)
+}
+
+// Unmarshaler describes a message that decodes itself.
+type Unmarshaler interface {
+	Unmarshal(data []byte) error
+}
+
+// Codec describes Protocol Buffers codec.
+type Codec struct {
+	marshal   func(v interface{}) ([]byte, error)
+	unmarshal func(data []byte, v interface{}) error
+}
+
+// New creates codec that uses protobuf runtime's functions.
+//
+// @param
+// - marshal {func} (the runtime's marshal function)
+// - unmarshal {func} (the runtime's unmarshal function)
+//
+// @return
+// - codec {Codec} (the protobuf codec)
+func New(marshal func(v interface{}) ([]byte, error), unmarshal func(data []byte, v interface{}) error) Codec {
+	return Codec{marshal: marshal, unmarshal: unmarshal}
+}
+
+// MediaType returns Protocol Buffers' media type.
+func (c Codec) MediaType() string {
+	return MediaType
+}
+
+// Marshal encodes message as Protocol Buffers.
+func (c Codec) Marshal(model interface{}) ([]byte, error) {
+	if c.marshal != nil {
+		return c.marshal(model)
+	}
+	if message, ok := model.(Marshaler); ok {
+		return message.Marshal()
+	}
+	return nil, fmt.Errorf("protobuf: %T is not a protobuf message", model)
+}
+
+// Unmarshal decodes Protocol Buffers data into message.
+func (c Codec) Unmarshal(data []byte, model interface{}) error {
+	if c.unmarshal != nil {
+		return c.unmarshal(data, model)
+	}
+	if message, ok := model.(Unmarshaler); ok {
+		return message.Unmarshal(data)
+	}
+	return fmt.Errorf("protobuf: %T is not a protobuf message", model)
+}
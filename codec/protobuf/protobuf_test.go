@@ -0,0 +1,47 @@
+package protobuf
+
+import (
+	"testing"
+
+	"github.com/phuc0302/go-server/expected_format"
+)
+
+type message struct {
+	value string
+}
+
+func (m *message) Marshal() ([]byte, error) {
+	return []byte(m.value), nil
+}
+
+func (m *message) Unmarshal(data []byte) error {
+	m.value = string(data)
+	return nil
+}
+
+func Test_Codec(t *testing.T) {
+	codec := Codec{}
+
+	// [Test 1] Message
+	data, err := codec.Marshal(&message{value: "sample"})
+	if err != nil {
+		t.Error(expectedFormat.Nil)
+	}
+	output := new(message)
+	if err := codec.Unmarshal(data, output); err != nil || output.value != "sample" {
+		t.Errorf(expectedFormat.StringButFoundString, "sample", output.value)
+	}
+
+	// [Test 2] Non message
+	if _, err := codec.Marshal("sample"); err == nil {
+		t.Error(expectedFormat.NotNil)
+	}
+
+	// [Test 3] Runtime's functions
+	codec = New(func(v interface{}) ([]byte, error) {
+		return []byte("runtime"), nil
+	}, nil)
+	if data, _ := codec.Marshal("sample"); string(data) != "runtime" {
+		t.Errorf(expectedFormat.StringButFoundString, "runtime", string(data))
+	}
+}
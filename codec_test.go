@@ -0,0 +1,70 @@
+package server
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/phuc0302/go-server/codec/cbor"
+	"github.com/phuc0302/go-server/codec/msgpack"
+	"github.com/phuc0302/go-server/expected_format"
+	"github.com/phuc0302/go-server/util"
+)
+
+func Test_Codecs(t *testing.T) {
+	defer os.Remove(Debug)
+	Initialize(true)
+	RegisterCodec(msgpack.Codec{})
+	RegisterCodec(cbor.Codec{})
+
+	// Setup test server
+	type item struct {
+		Name string `json:"name"`
+	}
+	BindPost("/codecs", HandleError(func(c *RequestContext) error {
+		var model item
+		if _, err := c.Bind(&model); err != nil {
+			return err
+		}
+		c.Output(util.Status200(), model)
+		return nil
+	}))
+
+	ts := httptest.NewServer(ServeHTTP())
+	defer ts.Close()
+
+	post := func(contentType string, accept string, body []byte) (*http.Response, []byte) {
+		request, _ := http.NewRequest("POST", ts.URL+"/codecs", bytes.NewReader(body))
+		request.Header.Set("Content-Type", contentType)
+		request.Header.Set("Accept", accept)
+
+		response, _ := http.DefaultClient.Do(request)
+		bytes, _ := ioutil.ReadAll(response.Body)
+		return response, bytes
+	}
+
+	// [Test 1] MessagePack in, CBOR out
+	data, _ := msgpack.Marshal(item{Name: "sample"})
+	response, body := post(msgpack.MediaType, cbor.MediaType, data)
+
+	var output item
+	if err := cbor.Unmarshal(body, &output); err != nil || output.Name != "sample" {
+		t.Errorf(expectedFormat.StringButFoundString, "sample", output.Name)
+	}
+	if contentType := response.Header.Get("Content-Type"); contentType != cbor.MediaType {
+		t.Errorf(expectedFormat.StringButFoundString, cbor.MediaType, contentType)
+	}
+
+	// [Test 2] Structured syntax suffix
+	if response, body = post("application/vnd.sample+json; charset=utf-8", "application/json", []byte("{\"name\":\"json\"}")); string(body) != "{\"name\":\"json\"}" {
+		t.Errorf(expectedFormat.StringButFoundString, "{\"name\":\"json\"}", string(body))
+	}
+
+	// [Test 3] Unsupported media type
+	if response, _ = post("image/png", "application/json", nil); response.StatusCode != 415 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 415, response.StatusCode)
+	}
+}
@@ -4,9 +4,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/Sirupsen/logrus"
@@ -41,12 +43,29 @@ type Config struct {
 	Host string `json:"host"`
 	Port int    `json:"port"`
 
+	// Proxy
+	TrustedProxies []string `json:"trusted_proxies"` // IPs or CIDRs
+
 	// Header
 	HeaderSize    int           `json:"header_size"`    // In KB
 	MultipartSize int64         `json:"multipart_size"` // In MB
 	ReadTimeout   time.Duration `json:"timeout_read"`   // In seconds
 	WriteTimeout  time.Duration `json:"timeout_write"`  // In seconds
 
+	// Body
+	MaxBodySize      int64            `json:"max_body_size"`                // In KB within file, 0 means unlimited
+	BodySizes        map[string]int64 `json:"body_sizes,omitempty"`         // Group or route's prefix -> size in KB
+	ContentBodySizes map[string]int64 `json:"content_body_sizes,omitempty"` // Content type -> size in KB
+
+	// Load shedding
+	MaxInflight          int           `json:"max_inflight"`            // 0 means unlimited
+	MaxInflightPerClient int           `json:"max_inflight_per_client"` // By client's IP, 0 means unlimited
+	QueueSize            int           `json:"queue_size"`              // Number of requests that can wait for a slot
+	QueueTimeout         time.Duration `json:"timeout_queue"`           // In milliseconds
+
+	// Response
+	AutoETag bool `json:"auto_etag"` // Generate strong ETag from dynamic response's body
+
 	// Log
 	LogLevel     string `json:"log_level"`
 	SlackURL     string `json:"slack_url"`
@@ -59,11 +78,28 @@ type Config struct {
 	RedirectPaths map[string]string `json:"redirect_paths"`
 	StaticFolders map[string]string `json:"static_folders"`
 
+	// Access
+	AccessRules map[string]AccessRule `json:"access_rules,omitempty"` // Path's prefix -> rule, "/" applies globally
+	Roles       map[string][]string   `json:"roles,omitempty"`        // Role -> permissions, "*" grants everything
+
+	// Maintenance
+	Maintenance map[string]Maintenance `json:"maintenance,omitempty"` // Group's prefix -> maintenance, "/" applies globally
+
+	// Security
+	SecurityHeaders   SecurityHeaders            `json:"security_headers"`
+	SecurityOverrides map[string]SecurityHeaders `json:"security_overrides,omitempty"` // Group's prefix -> headers
+
 	// Extensions
 	Extensions map[string]interface{} `json:"extensions,omitempty"`
 
 	// File's path
 	configPath string
+
+	// Derived data
+	trustedProxies []*net.IPNet
+	accessRules    map[string]accessRule
+	maintenance    map[string]maintenance
+	mutex          sync.RWMutex
 }
 
 // CreateConfig generates a default configuration file.
@@ -75,14 +111,16 @@ func CreateConfig(configFile string) {
 		configFile = fmt.Sprintf("%s/%s", configPath, configFile)
 	}
 
-	// Create default config
+	// Create default config, sizes are in bytes while running & Save converts them to file's units
 	config := &Config{
 		Host:          "localhost",
 		Port:          8080,
-		HeaderSize:    (5 << 10),
-		MultipartSize: (1 << 20),
+		HeaderSize:    (5 << 10),  // 5 KB
+		MultipartSize: (1 << 20),  // 1 MB
+		MaxBodySize:   (10 << 20), // 10 MB
 		ReadTimeout:   (15 * time.Second),
 		WriteTimeout:  (15 * time.Second),
+		QueueTimeout:  (100 * time.Millisecond),
 		LogLevel:      "debug",
 		SlackURL:      "",
 		SlackIcon:     ":ghost:",
@@ -97,6 +135,13 @@ func CreateConfig(configFile string) {
 			"/assets":    "assets",
 			"/resources": "resources",
 		},
+		TrustedProxies: []string{"127.0.0.1", "::1"},
+		SecurityHeaders: SecurityHeaders{
+			StrictTransportSecurity: "max-age=31536000; includeSubDomains",
+			ContentTypeOptions:      "nosniff",
+			FrameOptions:            "SAMEORIGIN",
+			ReferrerPolicy:          "strict-origin-when-cross-origin",
+		},
 
 		configPath: configFile,
 	}
@@ -135,12 +180,21 @@ func LoadConfig(configFile string) *Config {
 		os.Exit(1)
 	}
 	config.configPath = configFile
+	config.prepare()
 
 	// Convert duration to seconds
 	config.HeaderSize <<= 10
 	config.MultipartSize <<= 20
+	config.MaxBodySize <<= 10
+	for key, size := range config.BodySizes {
+		config.BodySizes[key] = size << 10
+	}
+	for key, size := range config.ContentBodySizes {
+		config.ContentBodySizes[key] = size << 10
+	}
 	config.ReadTimeout *= time.Second
 	config.WriteTimeout *= time.Second
+	config.QueueTimeout *= time.Millisecond
 
 	// Define redirectPaths
 	redirectPaths = make(map[int]string, len(config.RedirectPaths))
@@ -183,8 +237,16 @@ func (c *Config) Save() {
 	// Revert changed
 	c.HeaderSize >>= 10
 	c.MultipartSize >>= 20
+	c.MaxBodySize >>= 10
+	for key, size := range c.BodySizes {
+		c.BodySizes[key] = size >> 10
+	}
+	for key, size := range c.ContentBodySizes {
+		c.ContentBodySizes[key] = size >> 10
+	}
 	c.ReadTimeout /= time.Second
 	c.WriteTimeout /= time.Second
+	c.QueueTimeout /= time.Millisecond
 
 	// Create new file
 	file, _ := os.Create(c.configPath)
@@ -196,8 +258,52 @@ func (c *Config) Save() {
 	// Revert changed
 	c.HeaderSize <<= 10
 	c.MultipartSize <<= 20
+	c.MaxBodySize <<= 10
+	for key, size := range c.BodySizes {
+		c.BodySizes[key] = size << 10
+	}
+	for key, size := range c.ContentBodySizes {
+		c.ContentBodySizes[key] = size << 10
+	}
 	c.ReadTimeout *= time.Second
 	c.WriteTimeout *= time.Second
+	c.QueueTimeout *= time.Millisecond
+}
+
+// ReloadConfig reloads access rules, roles & maintenance from configuration file without restarting server.
+//
+// @return
+// - err {error} (error during reload process)
+func ReloadConfig() error {
+	bytes, err := ioutil.ReadFile(Cfg.configPath)
+	if err != nil {
+		return err
+	}
+
+	var config Config
+	if err := json.Unmarshal(bytes, &config); err != nil {
+		return err
+	}
+
+	Cfg.mutex.Lock()
+	defer Cfg.mutex.Unlock()
+
+	Cfg.AccessRules = config.AccessRules
+	Cfg.accessRules = parseAccessRules(config.AccessRules)
+	Cfg.Roles = config.Roles
+	Cfg.Maintenance = config.Maintenance
+	Cfg.maintenance = parseMaintenance(config.Maintenance)
+	return nil
+}
+
+// prepare generates derived data from configuration.
+func (c *Config) prepare() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.trustedProxies = parseNetworks(c.TrustedProxies)
+	c.accessRules = parseAccessRules(c.AccessRules)
+	c.maintenance = parseMaintenance(c.Maintenance)
 }
 
 // GetExtension returns extension data that had been associated with input key.
@@ -33,6 +33,9 @@ func Test_LoadConfig(t *testing.T) {
 	if config.HeaderSize != 5120 {
 		t.Errorf(expectedFormat.NumberButFoundNumber, 5120, config.HeaderSize)
 	}
+	if config.MaxBodySize != 10<<20 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 10<<20, config.MaxBodySize)
+	}
 	if config.ReadTimeout != 15*time.Second {
 		t.Errorf(expectedFormat.NumberButFoundNumber, 15*time.Second, config.ReadTimeout)
 	}
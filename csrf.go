@@ -0,0 +1,94 @@
+package server
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/phuc0302/go-server/util"
+)
+
+// CSRF's default names.
+const (
+	CSRFCookie = "csrf_token"
+	CSRFField  = "csrf_token"
+	CSRFHeader = "X-CSRF-Token"
+)
+
+// csrfTokenKey is the extra's key that holds current CSRF token.
+const csrfTokenKey = "csrf_token"
+
+// CSRF generates double-submit cookie adapter to protect unsafe requests against cross-site submission.
+// Safe methods & requests which are authorized by bearer token will be skipped.
+//
+// @param
+// - exemptPrefixes {string} (a list of path's prefixes that will be skipped, e.g. "/api")
+//
+// @return
+// - adapter {Adapter} (the CSRF adapter)
+func CSRF(exemptPrefixes ...string) Adapter {
+	return func(f HandleContextFunc) HandleContextFunc {
+		return func(c *RequestContext) {
+			token := ""
+			if cookie, err := c.request.Cookie(CSRFCookie); err == nil && len(cookie.Value) > 0 {
+				token = cookie.Value
+			} else {
+				token = generateCSRFToken()
+				http.SetCookie(c.response, &http.Cookie{
+					Name:     CSRFCookie,
+					Value:    token,
+					Path:     "/",
+					Secure:   c.request.TLS != nil,
+					SameSite: http.SameSiteLaxMode,
+				})
+			}
+			c.SetExtra(csrfTokenKey, token)
+
+			/* Condition validation: validate unsafe request only */
+			if !isSafeMethod(c.Method) && !isBearerRequest(c) && !hasPrefixes(c.Path, exemptPrefixes) {
+				submitted := c.request.Header.Get(CSRFHeader)
+				if len(submitted) == 0 {
+					submitted = c.FormParams[CSRFField]
+				}
+
+				if len(submitted) == 0 || subtle.ConstantTimeCompare([]byte(submitted), []byte(token)) != 1 {
+					c.OutputStatus(util.Status403WithDescription("Invalid CSRF token."))
+					return
+				}
+			}
+			f(c)
+		}
+	}
+}
+
+// CSRFToken returns current CSRF token, will be empty if CSRF adapter is not in used.
+func (c *RequestContext) CSRFToken() string {
+	token, _ := c.GetExtra(csrfTokenKey).(string)
+	return token
+}
+
+// CSRFField returns a hidden input that carries current CSRF token.
+func (c *RequestContext) CSRFField() string {
+	return fmt.Sprintf("<input type=\"hidden\" name=\"%s\" value=\"%s\">", CSRFField, c.CSRFToken())
+}
+
+// generateCSRFToken generates new random token.
+func generateCSRFToken() string {
+	bytes := make([]byte, 32)
+	rand.Read(bytes)
+	return base64.RawURLEncoding.EncodeToString(bytes)
+}
+
+// isSafeMethod checks if a HTTP request method is safe or not.
+func isSafeMethod(method string) bool {
+	return method == Get || method == Head || method == Options
+}
+
+// isBearerRequest checks if request is authorized by bearer token or not.
+func isBearerRequest(c *RequestContext) bool {
+	authorization := c.request.Header.Get("Authorization")
+	return len(authorization) > 7 && strings.EqualFold(authorization[:7], "bearer ")
+}
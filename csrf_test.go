@@ -0,0 +1,99 @@
+package server
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/phuc0302/go-server/expected_format"
+	"github.com/phuc0302/go-server/util"
+)
+
+func Test_CSRF(t *testing.T) {
+	defer os.Remove(Debug)
+	Cfg = LoadConfig(Debug)
+
+	// Create test server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		context := CreateContext(w, r)
+		Adapt(func(c *RequestContext) {
+			c.OutputText(util.Status200(), c.CSRFToken())
+		}, CSRF("/api"))(context)
+	}))
+	defer ts.Close()
+
+	// [Test 1] Safe method will receive token
+	response, _ := http.Get(ts.URL)
+	bytes, _ := ioutil.ReadAll(response.Body)
+	token := string(bytes)
+
+	if response.StatusCode != 200 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 200, response.StatusCode)
+	}
+	if cookies := response.Cookies(); len(cookies) != 1 || cookies[0].Value != token {
+		t.Errorf(expectedFormat.StringButFoundString, token, cookies)
+	}
+
+	// [Test 2] Unsafe method without token
+	request, _ := http.NewRequest("POST", ts.URL, strings.NewReader("key=value"))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.AddCookie(&http.Cookie{Name: CSRFCookie, Value: token})
+	if response, _ = http.DefaultClient.Do(request); response.StatusCode != 403 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 403, response.StatusCode)
+	}
+
+	// [Test 3] Unsafe method with form token
+	request, _ = http.NewRequest("POST", ts.URL, strings.NewReader("csrf_token="+token))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.AddCookie(&http.Cookie{Name: CSRFCookie, Value: token})
+	if response, _ = http.DefaultClient.Do(request); response.StatusCode != 200 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 200, response.StatusCode)
+	}
+
+	// [Test 4] Unsafe method with mismatched header token
+	request, _ = http.NewRequest("DELETE", ts.URL, nil)
+	request.Header.Set(CSRFHeader, "invalid")
+	request.AddCookie(&http.Cookie{Name: CSRFCookie, Value: token})
+	if response, _ = http.DefaultClient.Do(request); response.StatusCode != 403 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 403, response.StatusCode)
+	}
+
+	// [Test 5] Bearer request & exempt path
+	request, _ = http.NewRequest("DELETE", ts.URL, nil)
+	request.Header.Set("Authorization", "Bearer token")
+	if response, _ = http.DefaultClient.Do(request); response.StatusCode != 200 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 200, response.StatusCode)
+	}
+
+	request, _ = http.NewRequest("DELETE", ts.URL+"/api/user", nil)
+	if response, _ = http.DefaultClient.Do(request); response.StatusCode != 200 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 200, response.StatusCode)
+	}
+}
+
+func Test_CSRF_OutputHTML(t *testing.T) {
+	filePath := "csrf.html"
+	defer os.Remove(filePath)
+	ioutil.WriteFile(filePath, []byte("<form>{{csrfField}}</form>"), 0644)
+
+	// Create test server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		context := CreateContext(w, r)
+		Adapt(func(c *RequestContext) {
+			c.OutputHTML(filePath, nil)
+		}, CSRF())(context)
+	}))
+	defer ts.Close()
+
+	response, _ := http.Get(ts.URL)
+	bytes, _ := ioutil.ReadAll(response.Body)
+	token := response.Cookies()[0].Value
+
+	expected := "<form><input type=\"hidden\" name=\"csrf_token\" value=\"" + token + "\"></form>"
+	if string(bytes) != expected {
+		t.Errorf(expectedFormat.StringButFoundString, expected, string(bytes))
+	}
+}
@@ -0,0 +1,48 @@
+package server
+
+import (
+	"errors"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/phuc0302/go-server/util"
+)
+
+// HandleErrorFunc defines type alias for request context func callback handler that returns error.
+//
+// @param
+// - context {RequestContext} (a RequestContext's instance, will be created by router)
+//
+// @return
+// - err {error} (the handler's failure, wrap status with AsError to control response)
+type HandleErrorFunc func(*RequestContext) error
+
+// HandleError converts HandleErrorFunc to HandleContextFunc. Status error will be rendered as it is,
+// other errors will be logged & rendered as 500.
+//
+// Example:
+// BindGet("/users/{id}", HandleError(func(c *RequestContext) error {
+//     return util.Status404().AsError()
+// }))
+//
+// @param
+// - f {HandleErrorFunc} (an implementation of HandleErrorFunc)
+//
+// @return
+// - func {HandleContextFunc} (a wrapper func that renders returned error)
+func HandleError(f HandleErrorFunc) HandleContextFunc {
+	return func(c *RequestContext) {
+		err := f(c)
+		if err == nil {
+			return
+		}
+
+		var statusError *util.StatusError
+		if errors.As(err, &statusError) {
+			c.OutputStatus(statusError.Status)
+			return
+		}
+
+		logrus.Warningf("%s %s: %s", c.Method, c.Path, err)
+		c.OutputStatus(util.Status500())
+	}
+}
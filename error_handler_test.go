@@ -0,0 +1,43 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/phuc0302/go-server/expected_format"
+	"github.com/phuc0302/go-server/util"
+)
+
+func Test_HandleError(t *testing.T) {
+	defer os.Remove(Debug)
+	Initialize(true)
+
+	// Setup test server
+	BindGet("/error/nil", HandleError(func(c *RequestContext) error {
+		c.OutputText(util.Status200(), "ok")
+		return nil
+	}))
+	BindGet("/error/status", HandleError(func(c *RequestContext) error {
+		return fmt.Errorf("could not find user: %w", util.Status404().AsError())
+	}))
+	BindGet("/error/generic", HandleError(func(c *RequestContext) error {
+		return fmt.Errorf("database is down")
+	}))
+	BindGet("/error/panic", HandleError(func(c *RequestContext) error {
+		panic(util.Status409())
+	}))
+
+	ts := httptest.NewServer(ServeHTTP())
+	defer ts.Close()
+
+	tests := map[string]int{"/error/nil": 200, "/error/status": 404, "/error/generic": 500, "/error/panic": 409}
+	for path, code := range tests {
+		response, _ := http.Get(ts.URL + path)
+		if response.StatusCode != code {
+			t.Errorf(expectedFormat.NumberButFoundNumber, code, response.StatusCode)
+		}
+	}
+}
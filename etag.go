@@ -0,0 +1,95 @@
+package server
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/phuc0302/go-server/util"
+)
+
+// SetETag assigns an entity tag to the response, it takes priority over automatic ETag.
+//
+// @param
+// - tag {string} (the opaque tag without quotes)
+// - weak {bool} (indicate flag if tag is a weak validator or not)
+func (c *RequestContext) SetETag(tag string, weak bool) {
+	etag := fmt.Sprintf("%q", tag)
+	if weak {
+		etag = "W/" + etag
+	}
+	c.response.Header().Set("ETag", etag)
+}
+
+// SetLastModified assigns last modification time to the response.
+//
+// @param
+// - modTime {time.Time} (the last modification time of the resource)
+func (c *RequestContext) SetLastModified(modTime time.Time) {
+	c.response.Header().Set("Last-Modified", modTime.UTC().Format(http.TimeFormat))
+}
+
+// writeBody writes response, conditional GET will be answered with 304 if possible.
+//
+// @param
+// - status {util.Status} (the response's status)
+// - contentType {string} (the response's content type)
+// - data {[]byte} (the response's body)
+func (c *RequestContext) writeBody(status *util.Status, contentType string, data []byte) {
+	header := c.response.Header()
+	header.Set("Content-Type", contentType)
+
+	if status.Code == http.StatusOK && (c.Method == Get || c.Method == Head) {
+		if len(header.Get("ETag")) == 0 && Cfg != nil && Cfg.AutoETag {
+			header.Set("ETag", fmt.Sprintf("\"%x\"", sha1.Sum(data)))
+		}
+
+		if c.isNotModified() {
+			header.Del("Content-Type")
+			header.Del("Content-Length")
+			c.response.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+
+	c.response.WriteHeader(status.Code)
+	c.response.Write(data)
+}
+
+// isNotModified evaluates If-None-Match & If-Modified-Since against response's validators.
+//
+// @return
+// - flag {bool} (indicate flag if client's copy is still fresh or not)
+func (c *RequestContext) isNotModified() bool {
+	header := c.response.Header()
+
+	// If-None-Match takes precedence over If-Modified-Since
+	if ifNoneMatch := c.request.Header.Get("If-None-Match"); len(ifNoneMatch) > 0 {
+		etag := header.Get("ETag")
+		if len(etag) == 0 {
+			return false
+		}
+
+		for _, candidate := range strings.Split(ifNoneMatch, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+
+	if ifModifiedSince := c.request.Header.Get("If-Modified-Since"); len(ifModifiedSince) > 0 {
+		lastModified, err := http.ParseTime(header.Get("Last-Modified"))
+		if err != nil {
+			return false
+		}
+
+		if since, err := http.ParseTime(ifModifiedSince); err == nil {
+			return !lastModified.Truncate(time.Second).After(since)
+		}
+	}
+	return false
+}
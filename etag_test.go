@@ -0,0 +1,115 @@
+package server
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/phuc0302/go-server/expected_format"
+	"github.com/phuc0302/go-server/util"
+)
+
+func Test_AutoETag(t *testing.T) {
+	defer os.Remove(Debug)
+	Cfg = LoadConfig(Debug)
+	Cfg.AutoETag = true
+
+	// Create test server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		context := CreateContext(w, r)
+		context.OutputJSON(util.Status200(), map[string]string{"apple": "apple"})
+	}))
+	defer ts.Close()
+
+	// [Test 1] Full response
+	etag := fmt.Sprintf("\"%x\"", sha1.Sum([]byte("{\"apple\":\"apple\"}")))
+	response, _ := http.Get(ts.URL)
+	if response.Header.Get("ETag") != etag {
+		t.Errorf(expectedFormat.StringButFoundString, etag, response.Header.Get("ETag"))
+	}
+
+	// [Test 2] Conditional request
+	request, _ := http.NewRequest("GET", ts.URL, nil)
+	request.Header.Set("If-None-Match", "\"other\", "+etag)
+
+	response, _ = http.DefaultClient.Do(request)
+	bytes, _ := ioutil.ReadAll(response.Body)
+	if response.StatusCode != 304 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 304, response.StatusCode)
+	}
+	if len(bytes) != 0 {
+		t.Errorf(expectedFormat.StringButFoundString, "", string(bytes))
+	}
+
+	// [Test 3] Stale copy
+	request.Header.Set("If-None-Match", "\"other\"")
+	if response, _ = http.DefaultClient.Do(request); response.StatusCode != 200 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 200, response.StatusCode)
+	}
+}
+
+func Test_SetETag(t *testing.T) {
+	modTime := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// Create test server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		context := CreateContext(w, r)
+		context.SetETag("v1", true)
+		context.SetLastModified(modTime)
+		context.OutputText(util.Status200(), "Sample test!")
+	}))
+	defer ts.Close()
+
+	// [Test 1] Weak comparison
+	request, _ := http.NewRequest("GET", ts.URL, nil)
+	request.Header.Set("If-None-Match", "\"v1\"")
+	if response, _ := http.DefaultClient.Do(request); response.StatusCode != 304 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 304, response.StatusCode)
+	}
+
+	// [Test 2] If-Modified-Since
+	request, _ = http.NewRequest("GET", ts.URL, nil)
+	request.Header.Set("If-Modified-Since", modTime.Format(http.TimeFormat))
+	if response, _ := http.DefaultClient.Do(request); response.StatusCode != 304 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 304, response.StatusCode)
+	}
+
+	request.Header.Set("If-Modified-Since", modTime.Add(-time.Hour).Format(http.TimeFormat))
+	if response, _ := http.DefaultClient.Do(request); response.StatusCode != 200 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 200, response.StatusCode)
+	}
+
+	// [Test 3] Unsafe method is never conditional
+	request, _ = http.NewRequest("POST", ts.URL, nil)
+	request.Header.Set("If-None-Match", "*")
+	if response, _ := http.DefaultClient.Do(request); response.StatusCode != 200 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 200, response.StatusCode)
+	}
+}
+
+func Test_OutputHTML_ExecuteError(t *testing.T) {
+	filePath := "execute.html"
+	defer os.Remove(filePath)
+	ioutil.WriteFile(filePath, []byte("<p>{{.Secret.Field}}</p>"), 0644)
+
+	// Create test server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		CreateContext(w, r).OutputHTML(filePath, struct{ Name string }{"name"})
+	}))
+	defer ts.Close()
+
+	response, _ := http.Get(ts.URL)
+	bytes, _ := ioutil.ReadAll(response.Body)
+	if response.StatusCode != 500 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 500, response.StatusCode)
+	}
+	if strings.Contains(string(bytes), "Secret") {
+		t.Errorf(expectedFormat.StringButFoundString, "a generic error", string(bytes))
+	}
+}
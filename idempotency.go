@@ -0,0 +1,120 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/phuc0302/go-server/util"
+)
+
+// Idempotency's header names.
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotencyReplayedHeader = "Idempotent-Replayed"
+)
+
+// Idempotency generates adapter that replays stored response for repeated POST & PATCH requests
+// which carry the same Idempotency-Key. Keys are scoped by caller, which is the authenticated subject,
+// the request's credentials or client's IP, so that a client can not replay other client's response.
+//
+// @param
+// - store {IdempotencyStore} (the storage for processed requests, in-memory store will be used if nil)
+// - ttl {time.Duration} (how long a key will be remembered)
+//
+// @return
+// - adapter {Adapter} (the idempotency adapter)
+func Idempotency(store IdempotencyStore, ttl time.Duration) Adapter {
+	if store == nil {
+		store = NewMemoryIdempotencyStore()
+	}
+
+	return func(f HandleContextFunc) HandleContextFunc {
+		return func(c *RequestContext) {
+			idempotencyKey := c.request.Header.Get(IdempotencyKeyHeader)
+
+			/* Condition validation: only POST & PATCH with key are idempotent */
+			if len(idempotencyKey) == 0 || (c.Method != Post && c.Method != Patch) {
+				f(c)
+				return
+			}
+
+			body, err := c.readBody()
+			if err != nil {
+				c.OutputStatus(bodyErrorStatus(err))
+				return
+			}
+			if len(body) == 0 && c.request.PostForm != nil {
+				body = []byte(c.request.PostForm.Encode())
+			}
+			fingerprint := generateFingerprint(body)
+
+			// Keys are scoped by caller & route so that clients will not collide across endpoints
+			key := idempotencyCaller(c) + " " + c.Method + " " + c.Path + " " + idempotencyKey
+			if record, reserved := store.Reserve(key, fingerprint, ttl); !reserved {
+				if record.Fingerprint != fingerprint {
+					c.OutputStatus(util.Status422WithDescription("Idempotency-Key had been used with a different request."))
+				} else if !record.Completed {
+					c.OutputStatus(util.Status409WithDescription("A request with the same Idempotency-Key is being processed."))
+				} else {
+					header := c.response.Header()
+					for name, values := range record.Header {
+						header[name] = values
+					}
+					header.Set(IdempotencyReplayedHeader, "true")
+
+					c.response.WriteHeader(record.Status)
+					c.response.Write(record.Body)
+				}
+				return
+			}
+
+			// Record response
+			recorder := &responseRecorder{ResponseWriter: c.response}
+			c.response = recorder
+			defer func() {
+				c.response = recorder.ResponseWriter
+
+				// Server's errors & panics are not remembered so that client is able to retry
+				if recovered := recover(); recovered != nil {
+					store.Release(key)
+					panic(recovered)
+				}
+				if recorder.status == 0 || recorder.streaming || recorder.status >= http.StatusInternalServerError {
+					store.Release(key)
+					return
+				}
+
+				header := make(http.Header)
+				for name, values := range recorder.Header() {
+					header[name] = values
+				}
+				store.Complete(key, &IdempotencyRecord{
+					Fingerprint: fingerprint,
+					Completed:   true,
+					Status:      recorder.status,
+					Header:      header,
+					Body:        recorder.body.Bytes(),
+				}, ttl)
+			}()
+			f(c)
+		}
+	}
+}
+
+// idempotencyCaller identifies who is making the request. Credentials are used when adapter runs
+// before authentication, client's IP is the last resort.
+func idempotencyCaller(c *RequestContext) string {
+	subjectExtractorMutex.RLock()
+	extractor := subjectExtractor
+	subjectExtractorMutex.RUnlock()
+
+	if subject, _ := extractor(c); len(subject) > 0 {
+		return "subject:" + subject
+	}
+	for _, header := range []string{"Authorization", APIKeyHeader} {
+		if credentials := c.request.Header.Get(header); len(credentials) > 0 {
+			return "credentials:" + generateFingerprint([]byte(credentials))
+		}
+	}
+	return "ip:" + c.ClientIP()
+}
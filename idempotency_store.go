@@ -0,0 +1,105 @@
+package server
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// IdempotencyRecord describes a request that had been processed, or is being processed, with an
+// Idempotency-Key.
+type IdempotencyRecord struct {
+	Fingerprint string
+	Completed   bool
+
+	// Stored response
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// IdempotencyStore describes a storage for idempotency records.
+type IdempotencyStore interface {
+	// Reserve atomically stores an in-flight record if key is not in used.
+	//
+	// @return
+	// - record {IdempotencyRecord} (existing record, nil if key had been reserved)
+	// - reserved {bool} (indicate flag if key had been reserved or not)
+	Reserve(key string, fingerprint string, ttl time.Duration) (record *IdempotencyRecord, reserved bool)
+
+	// Complete stores processed response for a reserved key.
+	Complete(key string, record *IdempotencyRecord, ttl time.Duration)
+
+	// Release removes a reserved key so that client is able to retry.
+	Release(key string)
+}
+
+// memoryIdempotencyStore describes an in-memory IdempotencyStore.
+type memoryIdempotencyStore struct {
+	records   map[string]*IdempotencyRecord
+	expiredAt map[string]time.Time
+	lastSweep time.Time
+	mutex     sync.Mutex
+}
+
+// NewMemoryIdempotencyStore creates new in-memory idempotency store.
+//
+// @return
+// - store {IdempotencyStore} (an in-memory store's new instance)
+func NewMemoryIdempotencyStore() IdempotencyStore {
+	return &memoryIdempotencyStore{
+		records:   make(map[string]*IdempotencyRecord),
+		expiredAt: make(map[string]time.Time),
+		lastSweep: time.Now(),
+	}
+}
+
+// Reserve atomically stores an in-flight record if key is not in used.
+func (s *memoryIdempotencyStore) Reserve(key string, fingerprint string, ttl time.Duration) (*IdempotencyRecord, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+
+	if record := s.records[key]; record != nil && now.Before(s.expiredAt[key]) {
+		return record, false
+	}
+
+	s.records[key] = &IdempotencyRecord{Fingerprint: fingerprint}
+	s.expiredAt[key] = now.Add(ttl)
+	return nil, true
+}
+
+// Complete stores processed response for a reserved key.
+func (s *memoryIdempotencyStore) Complete(key string, record *IdempotencyRecord, ttl time.Duration) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.records[key] = record
+	s.expiredAt[key] = time.Now().Add(ttl)
+}
+
+// Release removes a reserved key so that client is able to retry.
+func (s *memoryIdempotencyStore) Release(key string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.records, key)
+	delete(s.expiredAt, key)
+}
+
+// sweep removes expired records, at most once per minute.
+func (s *memoryIdempotencyStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+
+	for key, expiredAt := range s.expiredAt {
+		if now.After(expiredAt) {
+			delete(s.records, key)
+			delete(s.expiredAt, key)
+		}
+	}
+	s.lastSweep = now
+}
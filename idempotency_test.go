@@ -0,0 +1,130 @@
+package server
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/phuc0302/go-server/expected_format"
+	"github.com/phuc0302/go-server/util"
+)
+
+func Test_Idempotency(t *testing.T) {
+	defer os.Remove(Debug)
+	Initialize(true)
+
+	// Setup test server
+	counter := 0
+	BindPost("/orders", Adapt(func(c *RequestContext) {
+		var order map[string]string
+		c.BindJSON(&order)
+
+		counter++
+		c.OutputJSON(util.Status201(), map[string]string{"id": fmt.Sprintf("%d", counter), "item": order["item"]})
+	}, Idempotency(nil, time.Minute)))
+
+	ts := httptest.NewServer(ServeHTTP())
+	defer ts.Close()
+
+	post := func(key string, body string) (*http.Response, string) {
+		request, _ := http.NewRequest("POST", ts.URL+"/orders", strings.NewReader(body))
+		request.Header.Set("Content-Type", "application/json")
+		if len(key) > 0 {
+			request.Header.Set(IdempotencyKeyHeader, key)
+		}
+
+		response, _ := http.DefaultClient.Do(request)
+		bytes, _ := ioutil.ReadAll(response.Body)
+		return response, string(bytes)
+	}
+
+	// [Test 1] First request
+	response, body := post("key-1", "{\"item\":\"apple\"}")
+	if response.StatusCode != 201 || body != "{\"id\":\"1\",\"item\":\"apple\"}" {
+		t.Errorf(expectedFormat.StringButFoundString, "{\"id\":\"1\",\"item\":\"apple\"}", body)
+	}
+
+	// [Test 2] Repeated request is replayed
+	response, body = post("key-1", "{\"item\":\"apple\"}")
+	if response.StatusCode != 201 || body != "{\"id\":\"1\",\"item\":\"apple\"}" {
+		t.Errorf(expectedFormat.StringButFoundString, "{\"id\":\"1\",\"item\":\"apple\"}", body)
+	}
+	if response.Header.Get(IdempotencyReplayedHeader) != "true" {
+		t.Errorf(expectedFormat.StringButFoundString, "true", response.Header.Get(IdempotencyReplayedHeader))
+	}
+
+	// [Test 3] Same key with different body
+	if response, _ = post("key-1", "{\"item\":\"banana\"}"); response.StatusCode != 422 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 422, response.StatusCode)
+	}
+
+	// [Test 4] Request without key is never replayed
+	if _, body = post("", "{\"item\":\"apple\"}"); body != "{\"id\":\"2\",\"item\":\"apple\"}" {
+		t.Errorf(expectedFormat.StringButFoundString, "{\"id\":\"2\",\"item\":\"apple\"}", body)
+	}
+}
+
+func Test_MemoryIdempotencyStore(t *testing.T) {
+	store := NewMemoryIdempotencyStore()
+
+	// [Test 1] Reserve
+	if _, reserved := store.Reserve("key", "abc", time.Minute); !reserved {
+		t.Error("Expected key had been reserved.")
+	}
+	if record, reserved := store.Reserve("key", "abc", time.Minute); reserved || record.Completed {
+		t.Error("Expected key is in progress.")
+	}
+
+	// [Test 2] Release
+	store.Release("key")
+	if _, reserved := store.Reserve("key", "abc", time.Millisecond); !reserved {
+		t.Error("Expected key had been reserved.")
+	}
+
+	// [Test 3] Expiration
+	time.Sleep(5 * time.Millisecond)
+	if _, reserved := store.Reserve("key", "abc", time.Minute); !reserved {
+		t.Error("Expected expired key had been reserved again.")
+	}
+}
+
+func Test_Idempotency_Caller(t *testing.T) {
+	counter := 0
+	handler := Adapt(func(c *RequestContext) {
+		counter++
+		c.OutputText(util.Status201(), fmt.Sprintf("%d", counter))
+	}, Idempotency(nil, time.Minute))
+
+	// Request without body must not panic
+	post := func(authorization string) string {
+		request, _ := http.NewRequest("POST", "/orders", nil)
+		request.RemoteAddr = "198.51.100.7:1234"
+		request.Header.Set(IdempotencyKeyHeader, "key-1")
+		if len(authorization) > 0 {
+			request.Header.Set("Authorization", authorization)
+		}
+
+		recorder := httptest.NewRecorder()
+		handler(CreateContext(recorder, request))
+		return recorder.Body.String()
+	}
+
+	// [Test 1] Same caller is replayed
+	post("Bearer alice")
+	if body := post("Bearer alice"); body != "1" {
+		t.Errorf(expectedFormat.StringButFoundString, "1", body)
+	}
+
+	// [Test 2] Other callers can not replay alice's response
+	if body := post("Bearer bob"); body != "2" {
+		t.Errorf(expectedFormat.StringButFoundString, "2", body)
+	}
+	if body := post(""); body != "3" {
+		t.Errorf(expectedFormat.StringButFoundString, "3", body)
+	}
+}
@@ -0,0 +1,239 @@
+package server
+
+import (
+	"container/list"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/phuc0302/go-server/util"
+)
+
+// Limiter describes a concurrency limiter that keeps a short bounded queue & sheds the rest.
+type Limiter struct {
+	limit        int
+	queueSize    int
+	queueTimeout time.Duration
+
+	// Adaptive limit
+	minLimit      int
+	maxLimit      int
+	targetLatency time.Duration
+	lastDecrease  time.Time
+
+	// Per client limit
+	perClient int
+	clients   map[string]int
+
+	inflight int
+	waiters  *list.List
+	mutex    sync.Mutex
+}
+
+// NewLimiter creates new concurrency limiter.
+//
+// @param
+// - limit {int} (maximum number of in-flight requests)
+// - queueSize {int} (maximum number of requests that are waiting for a slot)
+// - queueTimeout {time.Duration} (maximum duration a request will wait for a slot)
+//
+// @return
+// - limiter {Limiter} (a Limiter's new instance)
+func NewLimiter(limit int, queueSize int, queueTimeout time.Duration) *Limiter {
+	/* Condition validation: validate limit */
+	if limit <= 0 {
+		panic("Limiter's limit must be greater than zero.")
+	}
+
+	return &Limiter{
+		limit:        limit,
+		queueSize:    queueSize,
+		queueTimeout: queueTimeout,
+		waiters:      list.New(),
+	}
+}
+
+// Adaptive enables adaptive limit, the limit will grow while observed latency is below target &
+// shrink when it is above.
+//
+// @param
+// - minLimit {int} (the lowest limit)
+// - maxLimit {int} (the highest limit)
+// - targetLatency {time.Duration} (the expected handler's latency)
+//
+// @return
+// - limiter {Limiter} (the same Limiter's instance)
+func (l *Limiter) Adaptive(minLimit int, maxLimit int, targetLatency time.Duration) *Limiter {
+	/* Condition validation: limit must never drop to zero */
+	if minLimit < 1 {
+		minLimit = 1
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.minLimit = minLimit
+	l.maxLimit = maxLimit
+	l.targetLatency = targetLatency
+	return l
+}
+
+// PerClient caps in-flight & queued requests of a single client, identified by ClientIP, so that one
+// client can not take every slot.
+//
+// @param
+// - limit {int} (maximum number of requests per client, 0 means unlimited)
+//
+// @return
+// - limiter {Limiter} (the same Limiter's instance)
+func (l *Limiter) PerClient(limit int) *Limiter {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.perClient = limit
+	l.clients = make(map[string]int)
+	return l
+}
+
+// Adapter limits in-flight requests of decorated handler, it can be used with Adapt func.
+//
+// @param
+// - f {HandleContextFunc} (the handler that will be limited)
+//
+// @return
+// - func {HandleContextFunc} (the limited handler)
+func (l *Limiter) Adapter(f HandleContextFunc) HandleContextFunc {
+	return func(c *RequestContext) {
+		ip := c.ClientIP()
+		if !l.acquire(ip) {
+			c.OutputHeader("Retry-After", l.retryAfter())
+			c.OutputStatus(util.Status503WithDescription("Server is busy."))
+			return
+		}
+
+		start := time.Now()
+		defer func() { l.release(ip, time.Since(start)) }()
+		f(c)
+	}
+}
+
+// Limit returns current limit.
+func (l *Limiter) Limit() int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.limit
+}
+
+// acquire waits for a free slot.
+//
+// @param
+// - ip {string} (the client's IP)
+//
+// @return
+// - ok {bool} (indicate flag if slot had been acquired or request should be rejected)
+func (l *Limiter) acquire(ip string) bool {
+	l.mutex.Lock()
+
+	/* Condition validation: reject fast if client already holds its share */
+	if l.perClient > 0 {
+		if l.clients[ip] >= l.perClient {
+			l.mutex.Unlock()
+			return false
+		}
+		l.clients[ip]++
+	}
+
+	if l.inflight < l.limit {
+		l.inflight++
+		l.mutex.Unlock()
+		return true
+	}
+
+	/* Condition validation: reject fast if queue is full */
+	if l.waiters.Len() >= l.queueSize {
+		l.releaseClient(ip)
+		l.mutex.Unlock()
+		return false
+	}
+
+	signal := make(chan struct{}, 1)
+	element := l.waiters.PushBack(signal)
+	l.mutex.Unlock()
+
+	timer := time.NewTimer(l.queueTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-signal:
+		return true
+
+	case <-timer.C:
+		l.mutex.Lock()
+		defer l.mutex.Unlock()
+
+		// Slot might had been granted while timer fired
+		select {
+		case <-signal:
+			return true
+		default:
+			l.waiters.Remove(element)
+			l.releaseClient(ip)
+			return false
+		}
+	}
+}
+
+// release frees a slot & hands it to the next waiting request if there is any.
+//
+// @param
+// - ip {string} (the client's IP)
+// - latency {time.Duration} (the observed handler's latency)
+func (l *Limiter) release(ip string, latency time.Duration) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.releaseClient(ip)
+	l.inflight--
+	if l.targetLatency > 0 {
+		if latency > l.targetLatency {
+			// Decrease at most once per target latency to avoid collapse on a burst of slow requests
+			if time.Since(l.lastDecrease) > l.targetLatency && l.limit > l.minLimit {
+				l.limit = l.limit * 9 / 10
+				if l.limit < l.minLimit {
+					l.limit = l.minLimit
+				}
+				l.lastDecrease = time.Now()
+			}
+		} else if l.limit < l.maxLimit {
+			l.limit++
+		}
+	}
+
+	for l.inflight < l.limit && l.waiters.Len() > 0 {
+		element := l.waiters.Front()
+		l.waiters.Remove(element)
+
+		l.inflight++
+		element.Value.(chan struct{}) <- struct{}{}
+	}
+}
+
+// releaseClient frees client's share, mutex must be held.
+func (l *Limiter) releaseClient(ip string) {
+	if l.perClient <= 0 {
+		return
+	}
+
+	if l.clients[ip]--; l.clients[ip] <= 0 {
+		delete(l.clients, ip)
+	}
+}
+
+// retryAfter returns number of seconds client should wait before retrying.
+func (l *Limiter) retryAfter() string {
+	seconds := int(l.queueTimeout / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	return strconv.Itoa(seconds)
+}
@@ -0,0 +1,118 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/phuc0302/go-server/expected_format"
+	"github.com/phuc0302/go-server/util"
+)
+
+func Test_Limiter(t *testing.T) {
+	limiter := NewLimiter(1, 1, 500*time.Millisecond)
+	started := make(chan bool, 3)
+	unblock := make(chan bool)
+
+	handler := Adapt(func(c *RequestContext) {
+		started <- true
+		<-unblock
+		c.OutputText(util.Status200(), "")
+	}, limiter.Adapter)
+
+	// Create test server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler(CreateContext(w, r))
+	}))
+	defer ts.Close()
+
+	results := make(chan int, 2)
+	request := func() {
+		response, _ := http.Get(ts.URL)
+		results <- response.StatusCode
+	}
+
+	// [Test 1] First request holds the slot, second one waits in queue
+	go request()
+	<-started
+	go request()
+	time.Sleep(50 * time.Millisecond)
+
+	// [Test 2] Queue is full
+	response, _ := http.Get(ts.URL)
+	if response.StatusCode != 503 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 503, response.StatusCode)
+	}
+	if response.Header.Get("Retry-After") != "1" {
+		t.Errorf(expectedFormat.StringButFoundString, "1", response.Header.Get("Retry-After"))
+	}
+
+	// [Test 3] Queued request will be served once slot is free
+	unblock <- true
+	<-started
+	unblock <- true
+
+	for i := 0; i < 2; i++ {
+		if status := <-results; status != 200 {
+			t.Errorf(expectedFormat.NumberButFoundNumber, 200, status)
+		}
+	}
+}
+
+func Test_Limiter_QueueTimeout(t *testing.T) {
+	limiter := NewLimiter(1, 1, 10*time.Millisecond)
+
+	if !limiter.acquire("127.0.0.1") {
+		t.Error("Expected slot had been acquired.")
+	}
+	if limiter.acquire("127.0.0.1") {
+		t.Error("Expected queued request had been timed out.")
+	}
+	if limiter.waiters.Len() != 0 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 0, limiter.waiters.Len())
+	}
+}
+
+func Test_Limiter_Adaptive(t *testing.T) {
+	limiter := NewLimiter(10, 0, 0).Adaptive(5, 12, 10*time.Millisecond)
+
+	// [Test 1] Fast requests grow the limit up to max
+	for i := 0; i < 5; i++ {
+		limiter.acquire("127.0.0.1")
+		limiter.release("127.0.0.1", time.Millisecond)
+	}
+	if limit := limiter.Limit(); limit != 12 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 12, limit)
+	}
+
+	// [Test 2] Slow requests shrink the limit
+	limiter.acquire("127.0.0.1")
+	limiter.release("127.0.0.1", time.Second)
+	if limit := limiter.Limit(); limit != 10 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 10, limit)
+	}
+}
+
+func Test_Limiter_PerClient(t *testing.T) {
+	limiter := NewLimiter(3, 0, 0).PerClient(2)
+
+	// [Test 1] A single client can not take every slot
+	limiter.acquire("198.51.100.7")
+	limiter.acquire("198.51.100.7")
+	if limiter.acquire("198.51.100.7") {
+		t.Error("Expected client's request had been rejected.")
+	}
+	if !limiter.acquire("198.51.100.8") {
+		t.Error("Expected other client's request had been accepted.")
+	}
+
+	// [Test 2] Released share is available again
+	limiter.release("198.51.100.7", time.Millisecond)
+	if !limiter.acquire("198.51.100.7") {
+		t.Error("Expected client's request had been accepted.")
+	}
+	if count := limiter.clients["198.51.100.8"]; count != 1 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 1, count)
+	}
+}
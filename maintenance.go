@@ -0,0 +1,118 @@
+package server
+
+import (
+	"crypto/subtle"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/phuc0302/go-server/util"
+)
+
+// MaintenanceBypassHeader is the header that carries maintenance's bypass token.
+const MaintenanceBypassHeader = "X-Maintenance-Bypass"
+
+// Maintenance describes maintenance mode of the whole server or a group.
+type Maintenance struct {
+	Enabled      bool     `json:"enabled"`
+	SentinelFile string   `json:"sentinel_file,omitempty"` // Maintenance is also enabled while this file exists
+	RetryAfter   int      `json:"retry_after,omitempty"`   // In seconds, default is 60
+	Message      string   `json:"message,omitempty"`       // Status's description
+	BypassPaths  []string `json:"bypass_paths,omitempty"`  // Path's prefixes, e.g. health checks
+	BypassIPs    []string `json:"bypass_ips,omitempty"`    // IPs or CIDRs
+	BypassTokens []string `json:"bypass_tokens,omitempty"` // Values of X-Maintenance-Bypass header
+}
+
+// maintenance describes a parsed Maintenance.
+type maintenance struct {
+	Maintenance
+	bypassIPs []*net.IPNet
+}
+
+// SetMaintenance turns maintenance mode on or off at runtime, global maintenance should be defined
+// with "/" prefix. Bypass lists will be kept if maintenance had been defined in configuration file.
+//
+// @param
+// - prefix {string} (the group's prefix)
+// - enabled {bool} (indicate flag if maintenance is on or off)
+func SetMaintenance(prefix string, enabled bool) {
+	Cfg.mutex.Lock()
+	defer Cfg.mutex.Unlock()
+
+	if Cfg.Maintenance == nil {
+		Cfg.Maintenance = make(map[string]Maintenance)
+	}
+	mode := Cfg.Maintenance[prefix]
+	mode.Enabled = enabled
+	Cfg.Maintenance[prefix] = mode
+	Cfg.maintenance = parseMaintenance(Cfg.Maintenance)
+}
+
+// parseMaintenance parses maintenance modes.
+//
+// @param
+// - modes {map[string]Maintenance} (group's prefix -> maintenance)
+//
+// @return
+// - parsedModes {map[string]maintenance} (group's prefix -> parsed maintenance)
+func parseMaintenance(modes map[string]Maintenance) map[string]maintenance {
+	parsedModes := make(map[string]maintenance, len(modes))
+	for prefix, mode := range modes {
+		if mode.RetryAfter <= 0 {
+			mode.RetryAfter = 60
+		}
+		if len(mode.Message) == 0 {
+			mode.Message = "Service is under maintenance."
+		}
+		parsedModes[prefix] = maintenance{
+			Maintenance: mode,
+			bypassIPs:   parseNetworks(mode.BypassIPs),
+		}
+	}
+	return parsedModes
+}
+
+// checkMaintenance rejects request with 503 if any maintenance that matches request's path is on &
+// request is not allowed to bypass it.
+//
+// @param
+// - w {http.ResponseWriter} (the response writer)
+// - r {http.Request} (the request)
+// - path {string} (request's cleaned path)
+// - ip {string} (client's IP)
+func checkMaintenance(w http.ResponseWriter, r *http.Request, path string, ip string) {
+	Cfg.mutex.RLock()
+	defer Cfg.mutex.RUnlock()
+
+	for prefix, mode := range Cfg.maintenance {
+		if !hasPathPrefix(path, prefix) || !mode.isActive() || mode.isBypassed(r, path, ip) {
+			continue
+		}
+
+		w.Header().Set("Retry-After", strconv.Itoa(mode.RetryAfter))
+		panic(util.Status503WithDescription(mode.Message))
+	}
+}
+
+// isActive checks if maintenance is on or not.
+func (m maintenance) isActive() bool {
+	return m.Enabled || (len(m.SentinelFile) > 0 && util.FileExisted(m.SentinelFile))
+}
+
+// isBypassed checks if request is allowed to bypass maintenance or not.
+func (m maintenance) isBypassed(r *http.Request, path string, ip string) bool {
+	if hasPrefixes(path, m.BypassPaths) || containsIP(m.bypassIPs, ip) {
+		return true
+	}
+
+	token := r.Header.Get(MaintenanceBypassHeader)
+	if len(token) == 0 {
+		return false
+	}
+	for _, bypassToken := range m.BypassTokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(bypassToken)) == 1 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/phuc0302/go-server/expected_format"
+	"github.com/phuc0302/go-server/util"
+)
+
+func Test_Maintenance(t *testing.T) {
+	defer os.Remove(Debug)
+	Initialize(true)
+
+	// Setup test server
+	BindGet("/health", func(c *RequestContext) {
+		c.OutputText(util.Status200(), "ok")
+	})
+	BindGet("/healthz", func(c *RequestContext) {
+		c.OutputText(util.Status200(), "ok")
+	})
+	GroupRoute("/orders", func() {
+		BindGet("/list", func(c *RequestContext) {
+			c.OutputText(util.Status200(), "orders")
+		})
+	})
+
+	ts := httptest.NewServer(ServeHTTP())
+	defer ts.Close()
+
+	get := func(path string, token string) *http.Response {
+		request, _ := http.NewRequest("GET", ts.URL+path, nil)
+		if len(token) > 0 {
+			request.Header.Set(MaintenanceBypassHeader, token)
+		}
+		response, _ := http.DefaultClient.Do(request)
+		return response
+	}
+
+	// [Test 1] Group maintenance from configuration file
+	Cfg.Maintenance = map[string]Maintenance{
+		"/":       {BypassPaths: []string{"/health"}, BypassTokens: []string{"secret"}, SentinelFile: "maintenance.flag"},
+		"/orders": {Enabled: true, RetryAfter: 120},
+	}
+	Cfg.Save()
+	if err := ReloadConfig(); err != nil {
+		t.Errorf(expectedFormat.Nil)
+	}
+
+	response := get("/orders/list", "")
+	if response.StatusCode != 503 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 503, response.StatusCode)
+	}
+	if retryAfter := response.Header.Get("Retry-After"); retryAfter != "120" {
+		t.Errorf(expectedFormat.StringButFoundString, "120", retryAfter)
+	}
+	if response = get("/health", ""); response.StatusCode != 200 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 200, response.StatusCode)
+	}
+
+	// [Test 2] Runtime toggle
+	SetMaintenance("/orders", false)
+	if response = get("/orders/list", ""); response.StatusCode != 200 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 200, response.StatusCode)
+	}
+
+	// [Test 3] Sentinel file & bypass token
+	ioutil.WriteFile("maintenance.flag", nil, 0644)
+	defer os.Remove("maintenance.flag")
+
+	if response = get("/orders/list", ""); response.StatusCode != 503 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 503, response.StatusCode)
+	}
+	if response = get("/orders/list", "secret"); response.StatusCode != 200 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 200, response.StatusCode)
+	}
+	if response = get("/health", ""); response.StatusCode != 200 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 200, response.StatusCode)
+	}
+
+	// [Test 4] Bypass path matches on path segment boundary
+	if response = get("/healthz", ""); response.StatusCode != 503 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 503, response.StatusCode)
+	}
+}
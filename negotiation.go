@@ -0,0 +1,261 @@
+package server
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/phuc0302/go-server/util"
+)
+
+// Encoder defines type alias for func that encodes model into response's body.
+//
+// @param
+// - model {interface} (the response's model)
+//
+// @return
+// - data {[]byte} (the encoded body)
+// - err {error} (error during encode process)
+type Encoder func(model interface{}) ([]byte, error)
+
+// mediaEncoder describes a registered Encoder.
+type mediaEncoder struct {
+	mediaType string
+	encode    Encoder
+}
+
+var (
+	encoders = []mediaEncoder{
+		{mediaType: "application/json", encode: json.Marshal},
+		{mediaType: "application/xml", encode: encodeXML},
+		{mediaType: "text/plain", encode: encodeText},
+	}
+	encodersMutex sync.RWMutex
+)
+
+// RegisterEncoder associates encoder with media type, it replaces existing encoder of the same media
+// type. The first registered encoder ("application/json") is used when client accepts anything.
+//
+// @param
+// - mediaType {string} (the media type, e.g. "application/msgpack")
+// - encode {Encoder} (the encoder)
+func RegisterEncoder(mediaType string, encode Encoder) {
+	/* Condition validation: only accept function */
+	if encode == nil {
+		panic("Encoder must not be nil.")
+	}
+	mediaType = strings.ToLower(mediaType)
+
+	encodersMutex.Lock()
+	defer encodersMutex.Unlock()
+
+	for idx := range encoders {
+		if encoders[idx].mediaType == mediaType {
+			encoders[idx].encode = encode
+			return
+		}
+	}
+	encoders = append(encoders, mediaEncoder{mediaType: mediaType, encode: encode})
+}
+
+// Output returns model in the format that client prefers according to Accept header, client will
+// receive 406 if none of registered encoders is acceptable. If model could not be encoded, the next
+// acceptable encoder is tried, client will receive 500 if all of them fail.
+func (c *RequestContext) Output(status *util.Status, model interface{}) {
+	addVary(c.response.Header(), "Accept")
+
+	acceptables := negotiateEncoders(c.request.Header.Get("Accept"))
+	if len(acceptables) == 0 {
+		c.outputProblem(util.Status406())
+		return
+	}
+
+	for _, encoder := range acceptables {
+		data, err := encoder.encode(model)
+		if err != nil {
+			logrus.Warningf("Could not encode %T as %s: %s", model, encoder.mediaType, err)
+			continue
+		}
+
+		c.writeBody(status, encoder.mediaType, data)
+		return
+	}
+	c.outputProblem(util.Status500())
+}
+
+// writeProblem writes an status to response writer in the format that client prefers, JSON will be
+// used if none of registered encoders is acceptable.
+func writeProblem(w http.ResponseWriter, r *http.Request, status *util.Status) {
+	var accept string
+	if r != nil {
+		accept = r.Header.Get("Accept")
+	}
+	mediaType, cause := "application/json", []byte(nil)
+	for _, encoder := range negotiateEncoders(accept) {
+		if data, err := encoder.encode(status); err == nil {
+			mediaType, cause = encoder.mediaType, data
+			break
+		}
+	}
+	if cause == nil {
+		cause, _ = json.Marshal(status)
+	}
+
+	header := w.Header()
+	addVary(header, "Accept")
+	header.Set("Content-Type", problemType(mediaType))
+	w.WriteHeader(status.Code)
+	w.Write(cause)
+}
+
+// addVary adds token to Vary header if it is not yet listed.
+func addVary(header http.Header, token string) {
+	if list := splitHeaderList(header.Values("Vary")); containsToken(list, token) || containsToken(list, "*") {
+		return
+	}
+	header.Add("Vary", token)
+}
+
+// problemType returns problem details' media type of JSON & XML, other media types stay the same.
+func problemType(mediaType string) string {
+	if strings.HasSuffix(mediaType, "/json") || strings.HasSuffix(mediaType, "/xml") {
+		return "application/problem+" + mediaType[strings.LastIndex(mediaType, "/")+1:]
+	}
+	return mediaType
+}
+
+// negotiateEncoders lists registered encoders that client accepts, the preferred one goes first.
+//
+// @param
+// - accept {string} (the request's Accept header)
+//
+// @return
+// - encoders {[]mediaEncoder} (the acceptable encoders, empty if none of them is acceptable)
+func negotiateEncoders(accept string) []mediaEncoder {
+	encodersMutex.RLock()
+	defer encodersMutex.RUnlock()
+
+	offers := make([]string, len(encoders))
+	for idx, encoder := range encoders {
+		offers[idx] = encoder.mediaType
+	}
+
+	indexes := negotiate(accept, offers)
+	acceptables := make([]mediaEncoder, len(indexes))
+	for idx, offerIdx := range indexes {
+		acceptables[idx] = encoders[offerIdx]
+	}
+	return acceptables
+}
+
+// acceptRange describes a media range of Accept header.
+type acceptRange struct {
+	mediaType string
+	quality   float64
+}
+
+// negotiate sorts acceptable offers by quality from highest to lowest, ties are broken by offers'
+// order.
+//
+// @param
+// - accept {string} (the request's Accept header, empty means everything is acceptable)
+// - offers {[]string} (the media types that server can produce, in server's preferred order)
+//
+// @return
+// - indexes {[]int} (the indexes of acceptable offers, empty if none of offers is acceptable)
+func negotiate(accept string, offers []string) []int {
+	indexes := make([]int, 0, len(offers))
+	if len(strings.TrimSpace(accept)) == 0 {
+		for idx := range offers {
+			indexes = append(indexes, idx)
+		}
+		return indexes
+	}
+	ranges := parseAccept(accept)
+
+	qualities := make([]float64, len(offers))
+	for idx, offer := range offers {
+		if qualities[idx] = matchQuality(ranges, offer); qualities[idx] > 0 {
+			indexes = append(indexes, idx)
+		}
+	}
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return qualities[indexes[i]] > qualities[indexes[j]]
+	})
+	return indexes
+}
+
+// matchQuality returns quality of the most specific media range that matches offer.
+func matchQuality(ranges []acceptRange, offer string) float64 {
+	offerType := offer[:strings.Index(offer, "/")+1]
+
+	quality, specificity := 0.0, -1
+	for _, r := range ranges {
+		var level int
+		switch {
+		case r.mediaType == offer:
+			level = 2
+		case r.mediaType == offerType+"*":
+			level = 1
+		case r.mediaType == "*/*":
+			level = 0
+		default:
+			continue
+		}
+
+		if level > specificity {
+			quality, specificity = r.quality, level
+		}
+	}
+	return quality
+}
+
+// parseAccept parses Accept header into media ranges.
+func parseAccept(accept string) []acceptRange {
+	var ranges []acceptRange
+	for _, item := range splitHeaderList([]string{accept}) {
+		mediaType, params, err := mime.ParseMediaType(item)
+		if err != nil {
+			continue
+		}
+
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+		ranges = append(ranges, acceptRange{mediaType: mediaType, quality: quality})
+	}
+	return ranges
+}
+
+// encodeXML encodes model as XML document.
+func encodeXML(model interface{}) ([]byte, error) {
+	data, err := xml.Marshal(model)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
+
+// encodeText encodes model as plain text.
+func encodeText(model interface{}) ([]byte, error) {
+	switch value := model.(type) {
+	case string:
+		return []byte(value), nil
+	case []byte:
+		return value, nil
+	case fmt.Stringer:
+		return []byte(value.String()), nil
+	default:
+		return []byte(fmt.Sprintf("%v", model)), nil
+	}
+}
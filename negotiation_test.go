@@ -0,0 +1,104 @@
+package server
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/phuc0302/go-server/expected_format"
+	"github.com/phuc0302/go-server/util"
+)
+
+func Test_Output(t *testing.T) {
+	defer os.Remove(Debug)
+	Initialize(true)
+
+	// Setup test server
+	BindGet("/negotiation", func(c *RequestContext) {
+		c.Output(util.Status200(), map[string]string{"name": "sample"})
+	})
+	BindGet("/negotiation/error", func(c *RequestContext) {
+		panic(util.Status409())
+	})
+
+	ts := httptest.NewServer(ServeHTTP())
+	defer ts.Close()
+
+	get := func(path string, accept string) (*http.Response, string) {
+		request, _ := http.NewRequest("GET", ts.URL+path, nil)
+		if len(accept) > 0 {
+			request.Header.Set("Accept", accept)
+		}
+
+		response, _ := http.DefaultClient.Do(request)
+		bytes, _ := ioutil.ReadAll(response.Body)
+		return response, string(bytes)
+	}
+
+	tests := []struct {
+		accept      string
+		contentType string
+	}{
+		{"", "application/json"},
+		{"*/*", "application/json"},
+		{"text/plain", "text/plain"},
+		{"application/json;q=0.5, text/*", "text/plain"},
+		{"text/*;q=0.9, text/plain;q=0.1, application/json;q=0.5", "application/json"},
+	}
+	for _, test := range tests {
+		if response, _ := get("/negotiation", test.accept); response.Header.Get("Content-Type") != test.contentType {
+			t.Errorf(expectedFormat.StringButFoundString, test.contentType, response.Header.Get("Content-Type"))
+		}
+	}
+
+	// [Test 1] Nothing is acceptable
+	if response, _ := get("/negotiation", "image/png"); response.StatusCode != 406 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 406, response.StatusCode)
+	}
+
+	// [Test 2] Vary is listed once
+	response, _ := get("/negotiation", "image/png")
+	if vary := strings.Join(response.Header.Values("Vary"), ", "); vary != "Accept" {
+		t.Errorf(expectedFormat.StringButFoundString, "Accept", vary)
+	}
+	header := http.Header{"Vary": {"Accept-Encoding, accept"}}
+	if addVary(header, "Accept"); len(header.Values("Vary")) != 1 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 1, len(header.Values("Vary")))
+	}
+
+	// [Test 3] Error body is negotiated
+	response, body := get("/negotiation/error", "text/plain")
+	if response.StatusCode != 409 || body != "409 Conflict" {
+		t.Errorf(expectedFormat.StringButFoundString, "409 Conflict", body)
+	}
+	if response, _ = get("/negotiation/error", "application/xml"); response.Header.Get("Content-Type") != "application/problem+xml" {
+		t.Errorf(expectedFormat.StringButFoundString, "application/problem+xml", response.Header.Get("Content-Type"))
+	}
+	if response, _ = get("/negotiation/error", ""); response.Header.Get("Content-Type") != "application/problem+json" {
+		t.Errorf(expectedFormat.StringButFoundString, "application/problem+json", response.Header.Get("Content-Type"))
+	}
+
+	// [Test 4] Browser prefers XML that could not encode map, the next acceptable encoder is used
+	response, body = get("/negotiation", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+	if response.StatusCode != 200 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 200, response.StatusCode)
+	}
+	if contentType := response.Header.Get("Content-Type"); contentType != "application/json" {
+		t.Errorf(expectedFormat.StringButFoundString, "application/json", contentType)
+	}
+	if body != `{"name":"sample"}` {
+		t.Errorf(expectedFormat.StringButFoundString, `{"name":"sample"}`, body)
+	}
+
+	// [Test 5] Every acceptable encoder fails, encoder's error is not sent to client
+	response, body = get("/negotiation", "application/xml")
+	if response.StatusCode != 500 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 500, response.StatusCode)
+	}
+	if strings.Contains(body, "unsupported type") {
+		t.Errorf(expectedFormat.StringButFoundString, "500 problem", body)
+	}
+}
@@ -0,0 +1,27 @@
+package server
+
+import "strings"
+
+// hasPathPrefix checks if path is prefix itself or lives under it, prefix only matches on segment
+// boundary: "/admin" matches "/admin" & "/admin/users" but not "/administrator".
+//
+// @param
+// - path {string} (request's cleaned path)
+// - prefix {string} (the group or route's prefix)
+//
+// @return
+// - flag {bool} (indicate flag if path is under prefix or not)
+func hasPathPrefix(path string, prefix string) bool {
+	prefix = strings.TrimSuffix(prefix, "/")
+	return len(prefix) == 0 || path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+// hasPrefixes checks if path is under any of prefixes.
+func hasPrefixes(path string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if hasPathPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,29 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/phuc0302/go-server/expected_format"
+)
+
+func Test_HasPathPrefix(t *testing.T) {
+	tests := []struct {
+		path     string
+		prefix   string
+		expected bool
+	}{
+		{"/admin", "/admin", true},
+		{"/admin/users", "/admin", true},
+		{"/admin/users", "/admin/", true},
+		{"/admin", "/admin/", true},
+		{"/administrator", "/admin", false},
+		{"/admin-public", "/admin", false},
+		{"/sample", "/", true},
+	}
+
+	for _, test := range tests {
+		if found := hasPathPrefix(test.path, test.prefix); found != test.expected {
+			t.Errorf(expectedFormat.BoolButFoundBool, test.expected, found)
+		}
+	}
+}
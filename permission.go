@@ -0,0 +1,126 @@
+package server
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/phuc0302/go-server/util"
+)
+
+const (
+	// SubjectName is the extra's key that holds authenticated subject, it is read by default extractor.
+	SubjectName = "subject_name"
+
+	// SubjectRoles is the extra's key that holds subject's roles, it is read by default extractor.
+	SubjectRoles = "subject_roles"
+)
+
+// SubjectExtractor defines type alias for func that identifies who is making the request.
+//
+// @param
+// - context {RequestContext} (a RequestContext's instance)
+//
+// @return
+// - subject {string} (the subject's name, empty means request is not authenticated)
+// - roles {[]string} (the subject's roles)
+type SubjectExtractor func(c *RequestContext) (subject string, roles []string)
+
+var (
+	subjectExtractor      SubjectExtractor = extractSubject
+	subjectExtractorMutex sync.RWMutex
+)
+
+// SetSubjectExtractor replaces the extractor that will be used by RequirePermissions.
+func SetSubjectExtractor(extractor SubjectExtractor) {
+	/* Condition validation: only accept function */
+	if extractor == nil {
+		panic("Subject extractor must not be nil.")
+	}
+
+	subjectExtractorMutex.Lock()
+	defer subjectExtractorMutex.Unlock()
+	subjectExtractor = extractor
+}
+
+// RequirePermissions generates adapter that rejects request with 403 if subject's roles do not grant
+// all permissions. Roles are defined in Cfg.Roles, request without subject will be rejected with 401.
+//
+// @param
+// - permissions {...string} (the required permissions)
+//
+// @return
+// - adapter {Adapter} (the permissions adapter)
+func RequirePermissions(permissions ...string) Adapter {
+	return func(f HandleContextFunc) HandleContextFunc {
+		return func(c *RequestContext) {
+			subjectExtractorMutex.RLock()
+			extractor := subjectExtractor
+			subjectExtractorMutex.RUnlock()
+
+			// Denials are logged with route's pattern, path might contain sensitive params
+			route := c.route
+			if len(route) == 0 {
+				route = c.Path
+			}
+
+			/* Condition validation: validate subject */
+			subject, roles := extractor(c)
+			if len(subject) == 0 {
+				logrus.Warningf("permission denied: %s %s, subject: none", c.Method, route)
+				c.outputProblem(util.Status401())
+				return
+			}
+
+			for _, permission := range permissions {
+				if !isPermissionGranted(roles, permission) {
+					logrus.Warningf("permission denied: %s %s, subject: %s, roles: %v, missing: %s", c.Method, route, subject, roles, permission)
+					c.outputProblem(util.Status403())
+					return
+				}
+			}
+			f(c)
+		}
+	}
+}
+
+// extractSubject is the default extractor, it reads subject & roles from extras. Subject falls back
+// to basic auth's user or API key's name.
+func extractSubject(c *RequestContext) (string, []string) {
+	subject, _ := c.GetExtra(SubjectName).(string)
+	if len(subject) == 0 {
+		subject, _ = c.GetExtra(BasicAuthUser).(string)
+	}
+	if len(subject) == 0 {
+		subject, _ = c.GetExtra(APIKeyName).(string)
+	}
+
+	roles, _ := c.GetExtra(SubjectRoles).([]string)
+	return subject, roles
+}
+
+// isPermissionGranted checks if any role grants permission or not. Role's permission can be "*" or
+// end with ".*" to grant a whole group.
+//
+// @param
+// - roles {[]string} (the subject's roles)
+// - permission {string} (the required permission)
+//
+// @return
+// - flag {bool} (indicate flag if permission is granted or not)
+func isPermissionGranted(roles []string, permission string) bool {
+	Cfg.mutex.RLock()
+	defer Cfg.mutex.RUnlock()
+
+	for _, role := range roles {
+		for _, granted := range Cfg.Roles[role] {
+			if granted == "*" || granted == permission {
+				return true
+			}
+			if strings.HasSuffix(granted, ".*") && strings.HasPrefix(permission, granted[:len(granted)-1]) {
+				return true
+			}
+		}
+	}
+	return false
+}
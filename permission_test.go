@@ -0,0 +1,83 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/phuc0302/go-server/expected_format"
+	"github.com/phuc0302/go-server/util"
+)
+
+func Test_RequirePermissions(t *testing.T) {
+	defer os.Remove(Debug)
+	Initialize(true)
+	Cfg.Roles = map[string][]string{
+		"admin":  {"*"},
+		"editor": {"posts.*"},
+		"viewer": {"posts.read"},
+	}
+
+	// Setup test server
+	authenticate := func(f HandleContextFunc) HandleContextFunc {
+		return func(c *RequestContext) {
+			if role := c.request.Header.Get("X-Role"); len(role) > 0 {
+				c.SetExtra(SubjectName, "user")
+				c.SetExtra(SubjectRoles, []string{role})
+			}
+			f(c)
+		}
+	}
+	routes := make(chan string, 1)
+	GroupRoute("/posts", func() {
+		BindGet("/read", func(c *RequestContext) {
+			c.OutputText(util.Status200(), "read")
+		})
+		BindGet("/write", Adapt(func(c *RequestContext) {
+			c.OutputText(util.Status200(), "write")
+		}, RequirePermissions("posts.write")))
+		BindGet("/{id}", func(c *RequestContext) {
+			routes <- c.route
+			c.OutputText(util.Status200(), c.PathParams["id"])
+		})
+	}, authenticate, RequirePermissions("posts.read"))
+
+	ts := httptest.NewServer(ServeHTTP())
+	defer ts.Close()
+
+	get := func(path string, role string) int {
+		request, _ := http.NewRequest("GET", ts.URL+path, nil)
+		if len(role) > 0 {
+			request.Header.Set("X-Role", role)
+		}
+		response, _ := http.DefaultClient.Do(request)
+		return response.StatusCode
+	}
+
+	tests := []struct {
+		path string
+		role string
+		code int
+	}{
+		{"/posts/read", "", 401},
+		{"/posts/read", "guest", 403},
+		{"/posts/read", "viewer", 200},
+		{"/posts/write", "viewer", 403},
+		{"/posts/write", "editor", 200},
+		{"/posts/write", "admin", 200},
+	}
+	for _, test := range tests {
+		if code := get(test.path, test.role); code != test.code {
+			t.Errorf(expectedFormat.NumberButFoundNumber, test.code, code)
+		}
+	}
+
+	// [Test 1] Context keeps route's pattern that denials are logged with
+	if code := get("/posts/1", "viewer"); code != 200 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 200, code)
+	}
+	if route := <-routes; route != "/posts/{id}" {
+		t.Errorf(expectedFormat.StringButFoundString, "/posts/{id}", route)
+	}
+}
@@ -2,7 +2,6 @@ package server
 
 import (
 	"bytes"
-	"encoding/json"
 	"fmt"
 	"net/http"
 	"strings"
@@ -18,6 +17,8 @@ func Recovery(w http.ResponseWriter, r *http.Request) {
 		var status *util.Status
 		if httpError, ok := err.(*util.Status); ok {
 			status = httpError
+		} else if statusError, ok := err.(*util.StatusError); ok {
+			status = statusError.Status
 		} else {
 			status = util.Status500()
 		}
@@ -26,11 +27,7 @@ func Recovery(w http.ResponseWriter, r *http.Request) {
 		if redirectURL := redirectPaths[status.Code]; len(redirectURL) > 0 {
 			http.Redirect(w, r, redirectURL, status.Code)
 		} else {
-			w.Header().Set("Content-Type", "application/problem+json")
-			w.WriteHeader(status.Code)
-
-			cause, _ := json.Marshal(status)
-			w.Write(cause)
+			writeProblem(w, r, status)
 		}
 
 		// Slack log
@@ -41,7 +38,7 @@ func Recovery(w http.ResponseWriter, r *http.Request) {
 			buffer.WriteString(fmt.Sprintf("%s %s %s\n", r.Proto, r.Method, r.URL.Path))
 
 			buffer.WriteString(fmt.Sprintf("%s: %s\n", "user-agent", r.UserAgent()))
-			buffer.WriteString(fmt.Sprintf("%s: %s\n", "address", r.RemoteAddr))
+			buffer.WriteString(fmt.Sprintf("%s: %s\n", "address", resolveClientAddress(r).ip))
 
 			// Write header
 			buffer.WriteString(fmt.Sprintf("%s: %s\n", "referer", r.Referer()))
@@ -0,0 +1,84 @@
+package server
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// reloadableFile describes a line based file that will be parsed again whenever it is changed.
+type reloadableFile struct {
+	name     string
+	filePath string
+	modTime  time.Time
+	size     int64
+	loaded   bool
+	failed   bool
+	mutex    sync.Mutex
+}
+
+// reload reads file again if it had been changed since last read. Empty lines & lines that start
+// with "#" are skipped. If file is removed or could not be read, parse receives no line so that
+// previous entries are dropped.
+//
+// @param
+// - parse {func([]string)} (the callback that receives file's lines, it is called only if file is changed)
+func (r *reloadableFile) reload(parse func(lines []string)) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	info, err := os.Stat(r.filePath)
+	if err != nil {
+		r.fail(parse)
+		return
+	}
+
+	/* Condition validation: skip if file is not changed */
+	if r.loaded && info.ModTime().Equal(r.modTime) && info.Size() == r.size {
+		return
+	}
+
+	file, err := os.Open(r.filePath)
+	if err != nil {
+		r.fail(parse)
+		return
+	}
+	defer file.Close()
+
+	lines := make([]string, 0)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		r.fail(parse)
+		return
+	}
+	parse(lines)
+
+	if r.failed {
+		logrus.Infof("%s file at: %s is available again", r.name, r.filePath)
+	}
+	r.loaded = true
+	r.failed = false
+	r.modTime = info.ModTime()
+	r.size = info.Size()
+}
+
+// fail drops previous entries & logs warning once until file could be read again.
+func (r *reloadableFile) fail(parse func(lines []string)) {
+	if !r.failed {
+		logrus.Warningf("could not read %s file at: %s", r.name, r.filePath)
+		parse(nil)
+	}
+	r.loaded = false
+	r.failed = true
+}
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/phuc0302/go-server/expected_format"
+)
+
+func Test_ReloadableFile(t *testing.T) {
+	filePath := "reloadable"
+	defer os.Remove(filePath)
+	ioutil.WriteFile(filePath, []byte("# comment\n\n  first  \nsecond\n"), 0600)
+
+	count := 0
+	var lines []string
+	parse := func(l []string) {
+		count++
+		lines = l
+	}
+	file := &reloadableFile{name: "test", filePath: filePath}
+
+	// [Test 1] Comments & empty lines are skipped
+	file.reload(parse)
+	if len(lines) != 2 || lines[0] != "first" || lines[1] != "second" {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 2, len(lines))
+	}
+
+	// [Test 2] Unchanged file is not parsed again
+	file.reload(parse)
+	if count != 1 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 1, count)
+	}
+
+	// [Test 3] Changed file is parsed again
+	ioutil.WriteFile(filePath, []byte("third\n"), 0600)
+	modTime := time.Now().Add(time.Minute)
+	os.Chtimes(filePath, modTime, modTime)
+
+	file.reload(parse)
+	if count != 2 || len(lines) != 1 || lines[0] != "third" {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 2, count)
+	}
+
+	// [Test 4] Removed file drops previous lines once
+	os.Remove(filePath)
+	file.reload(parse)
+	file.reload(parse)
+	if count != 3 || len(lines) != 0 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 3, count)
+	}
+
+	// [Test 5] Restored file is parsed again
+	ioutil.WriteFile(filePath, []byte("fourth\n"), 0600)
+	file.reload(parse)
+	if count != 4 || len(lines) != 1 || lines[0] != "fourth" {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 4, count)
+	}
+}
@@ -1,16 +1,21 @@
 package server
 
 import (
+	"bytes"
 	"crypto/sha1"
 	"encoding/json"
+	"encoding/xml"
 	"fmt"
 	"io/ioutil"
+	"mime"
 	"mime/multipart"
 	"net/http"
 	"net/url"
+	"path/filepath"
 	"strings"
 	"text/template"
 
+	"github.com/Sirupsen/logrus"
 	"github.com/julienschmidt/httprouter"
 	"github.com/phuc0302/go-server/util"
 )
@@ -22,10 +27,17 @@ type RequestContext struct {
 	Header      map[string]string
 	PathParams  map[string]string
 	QueryParams map[string]string
+	FormParams  map[string]string
 
 	request  *http.Request
 	response http.ResponseWriter
 	extra    map[string]interface{}
+	address  *clientAddress
+	route    string
+	body     []byte
+
+	queryValues url.Values
+	formValues  url.Values
 }
 
 // CreateContext creates new request context.
@@ -39,48 +51,53 @@ func CreateContext(response http.ResponseWriter, request *http.Request) *Request
 		extra:    make(map[string]interface{}),
 	}
 
-	// Format request headers
+	// Format request headers, keep original values. All occurrences are available via HeaderValues
 	if len(request.Header) > 0 {
 		context.Header = make(map[string]string)
 
 		for k, v := range request.Header {
-			if header := strings.ToLower(k); header == "authorization" {
-				context.Header[header] = v[0]
-			} else {
-				context.Header[header] = strings.ToLower(v[0])
+			if len(v) > 0 {
+				context.Header[strings.ToLower(k)] = v[0]
 			}
 		}
 	}
 
-	// Parse body context if neccessary
-	var params url.Values
-	switch context.Method {
+	// Parse query params for every method
+	if query := request.URL.Query(); len(query) > 0 {
+		context.queryValues = query
+		context.QueryParams = firstValues(query)
+	}
 
-	case Get:
-		params = request.URL.Query()
+	// Parse body params by content type
+	var params url.Values
+	if mediaType, _, err := mime.ParseMediaType(request.Header.Get("Content-Type")); err == nil {
+		switch mediaType {
 
-	case Patch, Post:
-		if contentType := context.Header["content-type"]; contentType == "application/x-www-form-urlencoded" {
-			if err := request.ParseForm(); err == nil {
-				params = request.Form
+		case "application/x-www-form-urlencoded":
+			body, err := context.readBody()
+			if err != nil {
+				break
 			}
-		} else if strings.HasPrefix(contentType, "multipart/form-data; boundary") {
+			if params, err = url.ParseQuery(string(body)); err == nil {
+				request.PostForm = params
+			}
+
+		case "multipart/form-data":
 			if err := request.ParseMultipartForm(Cfg.MultipartSize); err == nil {
 				params = request.MultipartForm.Value
+			} else if isBodyTooLarge(err) {
+				panic(util.Status413())
 			}
-		}
 
-	default:
-		break
+		default:
+			break
+		}
 	}
 
-	// Process params
+	// Process params, keep every value of repeated keys
 	if len(params) > 0 {
-		context.QueryParams = make(map[string]string)
-
-		for k, v := range params {
-			context.QueryParams[k] = v[0]
-		}
+		context.formValues = params
+		context.FormParams = firstValues(params)
 	}
 	return context
 }
@@ -91,7 +108,8 @@ func (c *RequestContext) BasicAuth() (username string, password string, ok bool)
 	return
 }
 
-// BindForm converts urlencode/multipart form to object.
+// BindForm converts urlencode/multipart form to object, slice fields receive every value of repeated
+// key. Query params will be used for keys that are not in request's body.
 //
 // Example:
 // type Form struct {
@@ -102,7 +120,35 @@ func (c *RequestContext) BasicAuth() (username string, password string, ok bool)
 // form := new(Form)
 // err := c.BindForm(testStruct)
 func (c *RequestContext) BindForm(inputForm interface{}) error {
-	return util.BindForm(c.QueryParams, inputForm)
+	values := make(map[string][]string, len(c.queryValues)+len(c.formValues))
+	for key, value := range c.queryValues {
+		values[key] = value
+	}
+	for key, value := range c.formValues {
+		values[key] = value
+	}
+	return util.BindFormValues(values, inputForm)
+}
+
+// QueryValues returns every value of a query parameter, e.g. "?tag=a&tag=b".
+func (c *RequestContext) QueryValues(name string) []string {
+	return c.queryValues[name]
+}
+
+// FormValues returns every value of a body parameter, e.g. multi-select form field.
+func (c *RequestContext) FormValues(name string) []string {
+	return c.formValues[name]
+}
+
+// firstValues collapses multi-value params to their first values.
+func firstValues(values url.Values) map[string]string {
+	params := make(map[string]string, len(values))
+	for k, v := range values {
+		if len(v) > 0 {
+			params[k] = v[0]
+		}
+	}
+	return params
 }
 
 // BindJSON converts json data to object.
@@ -115,16 +161,65 @@ func (c *RequestContext) BindForm(inputForm interface{}) error {
 // - err {error} (error message during parse process)
 func (c *RequestContext) BindJSON(jsonObject interface{}) (fingerprint string, err error) {
 	/* Condition validation: validate read process */
-	bytes, err := ioutil.ReadAll(c.request.Body)
+	bytes, err := c.readBody()
 	if err != nil {
 		return
 	}
 
-	fingerprint = fmt.Sprintf("%x", sha1.Sum(bytes))
+	fingerprint = generateFingerprint(bytes)
 	err = json.Unmarshal(bytes, jsonObject)
 	return
 }
 
+// BindXML converts xml data to object.
+//
+// @param
+// - xmlObject {interface} (an associated XML object model)
+//
+// @return
+// - fingerprint {string} (a hex string represents data's hash)
+// - err {error} (error message during parse process)
+func (c *RequestContext) BindXML(xmlObject interface{}) (fingerprint string, err error) {
+	/* Condition validation: validate read process */
+	bytes, err := c.readBody()
+	if err != nil {
+		return
+	}
+
+	fingerprint = generateFingerprint(bytes)
+	err = xml.Unmarshal(bytes, xmlObject)
+	return
+}
+
+// readBody reads raw body once, the body will still be available to following readers. Oversized
+// body panics with 413 like CreateContext does, thus client receives 413 whatever handler does with
+// bind's error.
+//
+// @return
+// - bytes {[]byte} (the raw body)
+// - err {error} (error message during read process)
+func (c *RequestContext) readBody() ([]byte, error) {
+	if c.body == nil && c.request.Body == nil {
+		c.body = []byte{}
+	} else if c.body == nil {
+		bytes, err := ioutil.ReadAll(c.request.Body)
+		if isBodyTooLarge(err) {
+			panic(util.Status413())
+		} else if err != nil {
+			return nil, err
+		}
+		c.body = bytes
+	}
+
+	c.request.Body = ioutil.NopCloser(bytes.NewReader(c.body))
+	return c.body, nil
+}
+
+// generateFingerprint returns a hex string represents data's hash.
+func generateFingerprint(data []byte) string {
+	return fmt.Sprintf("%x", sha1.Sum(data))
+}
+
 // MultipartFile returns an uploaded file by name.
 func (c *RequestContext) MultipartFile(name string) (multipart.File, *multipart.FileHeader, error) {
 	return c.request.FormFile(name)
@@ -173,38 +268,69 @@ func (c *RequestContext) OutputRedirect(status *util.Status, url string) {
 
 // OutputJSON returns a JSON.
 func (c *RequestContext) OutputJSON(status *util.Status, model interface{}) {
-	c.response.Header().Set("Content-Type", "application/json")
-	c.response.WriteHeader(status.Code)
 	data, _ := json.Marshal(model)
-	c.response.Write(data)
+	c.writeBody(status, "application/json", data)
+}
+
+// OutputXML returns a XML.
+func (c *RequestContext) OutputXML(status *util.Status, model interface{}) {
+	data, err := encodeXML(model)
+	if err != nil {
+		logrus.Warningf("Could not encode XML: %s", err)
+		c.outputProblem(util.Status500())
+		return
+	}
+	c.writeBody(status, "application/xml", data)
 }
 
 // OutputHTML returns a HTML page.
 func (c *RequestContext) OutputHTML(filePath string, model interface{}) {
-	if tmpl, err := template.ParseFiles(filePath); err == nil {
-		tmpl.Execute(c.response, model)
+	if tmpl, err := template.New(filepath.Base(filePath)).Funcs(c.templateFuncs()).ParseFiles(filePath); err == nil {
+		var buffer bytes.Buffer
+		if err := tmpl.Execute(&buffer, model); err != nil {
+			// Template's error exposes its internals, client only needs to know that rendering failed
+			logrus.Warningf("Could not render %s: %s", filePath, err)
+			c.OutputStatus(util.Status500())
+			return
+		}
+		c.writeBody(util.Status200(), "text/html; charset=utf-8", buffer.Bytes())
 	} else {
 		c.OutputStatus(util.Status404())
 	}
 }
 
-// OutputStatus returns an status JSON.
+// OutputStatus returns an status in the format that client prefers.
 func (c *RequestContext) OutputStatus(status *util.Status) {
 	if redirectURL := redirectPaths[status.Code]; len(redirectURL) > 0 {
 		c.OutputRedirect(status, redirectURL)
 	} else {
-		c.response.Header().Set("Content-Type", "application/problem+json")
-		c.response.WriteHeader(status.Code)
-		cause, _ := json.Marshal(status)
-		c.response.Write(cause)
+		c.outputProblem(status)
 	}
 }
 
 // OutputText returns a string.
 func (c *RequestContext) OutputText(status *util.Status, data string) {
-	c.response.Header().Set("Content-Type", "text/plain")
-	c.response.WriteHeader(status.Code)
-	c.response.Write([]byte(data))
+	c.writeBody(status, "text/plain", []byte(data))
+}
+
+// outputProblem returns an status without following redirect instructions.
+func (c *RequestContext) outputProblem(status *util.Status) {
+	writeProblem(c.response, c.request, status)
+}
+
+// templateFuncs returns helper funcs that are available to HTML templates.
+func (c *RequestContext) templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"csrfField": c.CSRFField,
+		"csrfToken": c.CSRFToken,
+		"cspNonce":  c.CSPNonce,
+		"flashes": func() []string {
+			if session := c.Session(); session != nil {
+				return session.Flashes()
+			}
+			return nil
+		},
+	}
 }
 
 // GetExtra returns extra data that had been associated with key if there is any.
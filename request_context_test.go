@@ -3,6 +3,7 @@ package server
 import (
 	"bytes"
 	"encoding/json"
+	"encoding/xml"
 	"fmt"
 	"io/ioutil"
 	"mime/multipart"
@@ -31,8 +32,8 @@ func Test_CreateRequestContext_GetRequest(t *testing.T) {
 			if len(context.Header) != 2 {
 				t.Errorf(expectedFormat.NumberButFoundNumber, 2, len(context.Header))
 			} else {
-				if context.Header["user-agent"] != "go-http-client/1.1" {
-					t.Errorf(expectedFormat.StringButFoundString, "go-http-client/1.1", context.Header["user-agent"])
+				if context.Header["user-agent"] != "Go-http-client/1.1" {
+					t.Errorf(expectedFormat.StringButFoundString, "Go-http-client/1.1", context.Header["user-agent"])
 				}
 				if context.Header["accept-encoding"] != "gzip" {
 					t.Errorf(expectedFormat.StringButFoundString, "gzip", context.Header["accept-encoding"])
@@ -79,10 +80,10 @@ func Test_CreateRequestContext_PostFormRequest(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		context := CreateContext(w, r)
 
-		if context.Header["content-type"] != "application/x-www-form-urlencoded" {
-			t.Errorf(expectedFormat.StringButFoundString, "application/x-www-form-urlencoded", context.Header["content-type"])
+		if context.Header["content-type"] != "APPLICATION/X-WWW-FORM-URLENCODED" {
+			t.Errorf(expectedFormat.StringButFoundString, "APPLICATION/X-WWW-FORM-URLENCODED", context.Header["content-type"])
 		}
-		if context.QueryParams != nil {
+		if context.FormParams != nil {
 			t.Error(expectedFormat.Nil)
 		}
 	}))
@@ -95,17 +96,17 @@ func Test_CreateRequestContext_PostFormRequestWithData(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		context := CreateContext(w, r)
 
-		if context.Header["content-type"] != "application/x-www-form-urlencoded" {
-			t.Errorf(expectedFormat.StringButFoundString, "application/x-www-form-urlencoded", context.Header["content-type"])
+		if context.Header["content-type"] != "APPLICATION/X-WWW-FORM-URLENCODED" {
+			t.Errorf(expectedFormat.StringButFoundString, "APPLICATION/X-WWW-FORM-URLENCODED", context.Header["content-type"])
 		}
-		if context.QueryParams == nil {
+		if context.FormParams == nil {
 			t.Error(expectedFormat.NotNil)
 		} else {
-			if context.QueryParams["userID"] != "1" {
-				t.Errorf(expectedFormat.StringButFoundString, "1", context.QueryParams["userID"])
+			if context.FormParams["userID"] != "1" {
+				t.Errorf(expectedFormat.StringButFoundString, "1", context.FormParams["userID"])
 			}
-			if context.QueryParams["profileID"] != "2" {
-				t.Errorf(expectedFormat.StringButFoundString, "2", context.QueryParams["profileID"])
+			if context.FormParams["profileID"] != "2" {
+				t.Errorf(expectedFormat.StringButFoundString, "2", context.FormParams["profileID"])
 			}
 		}
 
@@ -122,10 +123,10 @@ func Test_CreateRequestContext_PostMultipartRequest(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		context := CreateContext(w, r)
 
-		if context.Header["content-type"] != "multipart/form-data; boundary=gc0p4jq0m2yt08ju534c0p" {
-			t.Errorf(expectedFormat.StringButFoundString, "multipart/form-data; boundary=gc0p4jq0m2yt08ju534c0p", context.Header["content-type"])
+		if context.Header["content-type"] != "multipart/form-data; boundary=gc0p4Jq0M2Yt08jU534c0p" {
+			t.Errorf(expectedFormat.StringButFoundString, "multipart/form-data; boundary=gc0p4Jq0M2Yt08jU534c0p", context.Header["content-type"])
 		}
-		if context.QueryParams != nil {
+		if context.FormParams != nil {
 			t.Error(expectedFormat.Nil)
 		}
 	}))
@@ -145,17 +146,17 @@ func Test_CreateRequestContext_PostMultipartRequestWithData(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		context := CreateContext(w, r)
 
-		if context.Header["content-type"] != "multipart/form-data; boundary=gc0p4jq0m2yt08ju534c0p" {
-			t.Errorf(expectedFormat.StringButFoundString, "multipart/form-data; boundary=gc0p4jq0m2yt08ju534c0p", context.Header["content-type"])
+		if context.Header["content-type"] != "multipart/form-data; boundary=gc0p4Jq0M2Yt08jU534c0p" {
+			t.Errorf(expectedFormat.StringButFoundString, "multipart/form-data; boundary=gc0p4Jq0M2Yt08jU534c0p", context.Header["content-type"])
 		}
-		if context.QueryParams == nil {
+		if context.FormParams == nil {
 			t.Error(expectedFormat.NotNil)
 		} else {
-			if context.QueryParams["userID"] != "1" {
-				t.Errorf(expectedFormat.StringButFoundString, "1", context.QueryParams["userID"])
+			if context.FormParams["userID"] != "1" {
+				t.Errorf(expectedFormat.StringButFoundString, "1", context.FormParams["userID"])
 			}
-			if context.QueryParams["profileID"] != "2" {
-				t.Errorf(expectedFormat.StringButFoundString, "2", context.QueryParams["profileID"])
+			if context.FormParams["profileID"] != "2" {
+				t.Errorf(expectedFormat.StringButFoundString, "2", context.FormParams["profileID"])
 			}
 		}
 	}))
@@ -180,6 +181,33 @@ func Test_CreateRequestContext_PostMultipartRequestWithData(t *testing.T) {
 	http.DefaultClient.Do(request)
 }
 
+func Test_CreateRequestContext_EveryMethod(t *testing.T) {
+	// Create test server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		context := CreateContext(w, r)
+
+		if context.QueryParams["userID"] != "1" {
+			t.Errorf(expectedFormat.StringButFoundString, "1", context.QueryParams["userID"])
+		}
+		if context.Method == Put && context.FormParams["profileID"] != "2" {
+			t.Errorf(expectedFormat.StringButFoundString, "2", context.FormParams["profileID"])
+		}
+		if _, ok := context.QueryParams["profileID"]; ok {
+			t.Error("Expected body params are kept separately from query params.")
+		}
+	}))
+	defer ts.Close()
+
+	// [Test 1] Delete with query params
+	request, _ := http.NewRequest("DELETE", ts.URL+"?userID=1", nil)
+	http.DefaultClient.Do(request)
+
+	// [Test 2] Put with charset qualified form body
+	request, _ = http.NewRequest("PUT", ts.URL+"?userID=1", strings.NewReader("profileID=2"))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=UTF-8")
+	http.DefaultClient.Do(request)
+}
+
 func Test_BindForm(t *testing.T) {
 	// Create test server
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -202,6 +230,27 @@ func Test_BindForm(t *testing.T) {
 	http.Post(ts.URL, "application/x-www-form-urlencoded", strings.NewReader("userID=1&profileID=2"))
 }
 
+func Test_BindForm_MultiValues(t *testing.T) {
+	// Create test server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var form struct {
+			Tags []string `field:"tag"`
+		}
+
+		context := CreateContext(w, r)
+		context.BindForm(&form)
+
+		if tags := context.QueryValues("tag"); len(tags) != 2 || tags[1] != "b" {
+			t.Errorf(expectedFormat.NumberButFoundNumber, 2, len(tags))
+		}
+		if len(form.Tags) != 2 || form.Tags[0] != "a" {
+			t.Errorf(expectedFormat.NumberButFoundNumber, 2, len(form.Tags))
+		}
+	}))
+	defer ts.Close()
+	http.Get(ts.URL + "?tag=a&tag=b")
+}
+
 func Test_BindJSON(t *testing.T) {
 	// Create test server
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -227,6 +276,90 @@ func Test_BindJSON(t *testing.T) {
 	client.Do(request)
 }
 
+func Test_BindXML(t *testing.T) {
+	// Create test server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		context := CreateContext(w, r)
+
+		status := new(util.Status)
+		fingerprint, err := context.BindXML(status)
+
+		if err != nil || len(fingerprint) == 0 {
+			t.Error(expectedFormat.Nil)
+		}
+		if status.Code != 200 {
+			t.Errorf(expectedFormat.NumberButFoundNumber, 200, status.Code)
+		}
+		if status.Description != http.StatusText(200) {
+			t.Errorf(expectedFormat.StringButFoundString, http.StatusText(200), status.Description)
+		}
+	}))
+	defer ts.Close()
+	b, _ := xml.Marshal(util.Status200())
+
+	request, _ := http.NewRequest("POST", ts.URL, bytes.NewBuffer(b))
+	request.Header.Set("Content-Type", "application/xml")
+	http.DefaultClient.Do(request)
+}
+
+func Test_OutputXML(t *testing.T) {
+	// Create test server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		context := CreateContext(w, r)
+		context.OutputXML(util.Status200(), util.Status404())
+	}))
+	defer ts.Close()
+
+	response, _ := http.Get(ts.URL)
+	bytes, _ := ioutil.ReadAll(response.Body)
+
+	expected := xml.Header + "<problem xmlns=\"urn:ietf:rfc:7807\"><status>404</status><title>Not Found</title><detail>Not Found</detail></problem>"
+	if string(bytes) != expected {
+		t.Errorf(expectedFormat.StringButFoundString, expected, string(bytes))
+	}
+	if contentType := response.Header.Get("Content-Type"); contentType != "application/xml" {
+		t.Errorf(expectedFormat.StringButFoundString, "application/xml", contentType)
+	}
+}
+
+func Test_OutputXML_EncodeError(t *testing.T) {
+	// Create test server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		context := CreateContext(w, r)
+		context.OutputXML(util.Status200(), map[string]string{"name": "sample"})
+	}))
+	defer ts.Close()
+
+	response, _ := http.Get(ts.URL)
+	if response.StatusCode != 500 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 500, response.StatusCode)
+	}
+}
+
+func Test_Output_XMLFallback(t *testing.T) {
+	// Create test server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		context := CreateContext(w, r)
+		context.Output(util.Status200(), []map[string]int{{"count": 1}})
+	}))
+	defer ts.Close()
+
+	request, _ := http.NewRequest("GET", ts.URL, nil)
+	request.Header.Set("Accept", "application/xml, application/json;q=0.5")
+	response, _ := http.DefaultClient.Do(request)
+	bytes, _ := ioutil.ReadAll(response.Body)
+
+	if response.StatusCode != 200 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 200, response.StatusCode)
+	}
+	if contentType := response.Header.Get("Content-Type"); contentType != "application/json" {
+		t.Errorf(expectedFormat.StringButFoundString, "application/json", contentType)
+	}
+	if string(bytes) != `[{"count":1}]` {
+		t.Errorf(expectedFormat.StringButFoundString, `[{"count":1}]`, string(bytes))
+	}
+}
+
 func Test_OutputHeader(t *testing.T) {
 	// Create test server
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,34 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HeaderValue returns the first value of header with its original case, name is case-insensitive.
+func (c *RequestContext) HeaderValue(name string) string {
+	return c.request.Header.Get(name)
+}
+
+// HeaderValues returns every occurrence of header with their original case, name is
+// case-insensitive.
+func (c *RequestContext) HeaderValues(name string) []string {
+	return c.request.Header.Values(name)
+}
+
+// HeaderList returns comma separated items of every occurrence of header, e.g. Accept-Encoding.
+func (c *RequestContext) HeaderList(name string) []string {
+	return splitHeaderList(c.request.Header.Values(name))
+}
+
+// HeaderInt parses the first value of header as integer.
+func (c *RequestContext) HeaderInt(name string) (int64, error) {
+	return strconv.ParseInt(strings.TrimSpace(c.request.Header.Get(name)), 10, 64)
+}
+
+// HeaderTime parses the first value of header as HTTP date, e.g. If-Modified-Since.
+func (c *RequestContext) HeaderTime(name string) (time.Time, error) {
+	return http.ParseTime(c.request.Header.Get(name))
+}
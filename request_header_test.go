@@ -0,0 +1,52 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/phuc0302/go-server/expected_format"
+)
+
+func Test_RequestHeader(t *testing.T) {
+	modifiedSince := time.Date(2016, time.October, 1, 8, 0, 0, 0, time.UTC)
+
+	// Create test server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		context := CreateContext(w, r)
+
+		// [Test 1] Original case is preserved
+		if value := context.HeaderValue("if-none-match"); value != "\"AbC\"" {
+			t.Errorf(expectedFormat.StringButFoundString, "\"AbC\"", value)
+		}
+		if value := context.Header["if-none-match"]; value != "\"AbC\"" {
+			t.Errorf(expectedFormat.StringButFoundString, "\"AbC\"", value)
+		}
+
+		// [Test 2] Every occurrence is kept
+		if values := context.HeaderValues("X-Tag"); len(values) != 2 || values[1] != "Second" {
+			t.Errorf(expectedFormat.NumberButFoundNumber, 2, len(values))
+		}
+		if list := context.HeaderList("X-Tag"); len(list) != 3 || list[2] != "Second" {
+			t.Errorf(expectedFormat.NumberButFoundNumber, 3, len(list))
+		}
+
+		// [Test 3] Typed values
+		if value, err := context.HeaderInt("X-Count"); err != nil || value != 42 {
+			t.Errorf(expectedFormat.NumberButFoundNumber, 42, value)
+		}
+		if value, err := context.HeaderTime("If-Modified-Since"); err != nil || !value.Equal(modifiedSince) {
+			t.Errorf(expectedFormat.StringButFoundString, modifiedSince, value)
+		}
+	}))
+	defer ts.Close()
+
+	request, _ := http.NewRequest("GET", ts.URL, nil)
+	request.Header.Set("If-None-Match", "\"AbC\"")
+	request.Header.Add("X-Tag", "First, Other")
+	request.Header.Add("X-Tag", "Second")
+	request.Header.Set("X-Count", "42")
+	request.Header.Set("If-Modified-Since", modifiedSince.Format(http.TimeFormat))
+	http.DefaultClient.Do(request)
+}
@@ -0,0 +1,337 @@
+package server
+
+import (
+	"bytes"
+	"container/list"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/phuc0302/go-server/util"
+)
+
+// CacheTagHeader is the response header that handler uses to tag cached responses, comma separated.
+const CacheTagHeader = "Cache-Tag"
+
+// ResponseCache describes a bounded LRU cache for GET responses.
+type ResponseCache struct {
+	capacity    int
+	ttl         time.Duration
+	varyHeaders []string
+
+	lru     *list.List
+	entries map[string]*list.Element
+	mutex   sync.Mutex
+}
+
+// cacheEntry describes a cached response.
+type cacheEntry struct {
+	key       string
+	url       string
+	tags      []string
+	header    http.Header
+	body      []byte
+	vary      []string // Response's Vary headers
+	variant   string   // Request's values of response's Vary headers
+	shared    bool     // Response is explicitly public, it can be served to authenticated requests
+	createdAt time.Time
+	expiredAt time.Time
+}
+
+// NewResponseCache creates new response cache.
+//
+// @param
+// - capacity {int} (maximum number of responses that will be kept)
+// - ttl {time.Duration} (default time to live if response does not define max-age)
+// - varyHeaders {string} (a list of request headers that will be part of cache's key)
+//
+// @return
+// - cache {ResponseCache} (a ResponseCache's new instance)
+func NewResponseCache(capacity int, ttl time.Duration, varyHeaders ...string) *ResponseCache {
+	/* Condition validation: validate capacity */
+	if capacity <= 0 {
+		panic("Response cache's capacity must be greater than zero.")
+	}
+
+	return &ResponseCache{
+		capacity:    capacity,
+		ttl:         ttl,
+		varyHeaders: varyHeaders,
+		lru:         list.New(),
+		entries:     make(map[string]*list.Element),
+	}
+}
+
+// Adapter caches GET responses of decorated handler, it can be used with Adapt func. Requests that
+// carry Authorization or Cookie header only share responses that are explicitly "public" or define
+// "s-maxage", the others bypass cache (RFC 9111 section 3.5). Cached response is only served to
+// requests that match it on every header listed in response's Vary, one variant is kept per URL.
+// Event streams & "Vary: *" responses are never cached.
+//
+// @param
+// - f {HandleContextFunc} (the handler that will be cached)
+//
+// @return
+// - func {HandleContextFunc} (the cached handler)
+func (rc *ResponseCache) Adapter(f HandleContextFunc) HandleContextFunc {
+	return func(c *RequestContext) {
+		/* Condition validation: only cache GET request */
+		if c.Method != Get {
+			f(c)
+			return
+		}
+
+		requestDirectives := parseCacheControl(c.request.Header.Get("Cache-Control"))
+		if _, ok := requestDirectives["no-store"]; ok {
+			f(c)
+			return
+		}
+
+		key := rc.generateKey(c)
+		isAuthenticated := len(c.request.Header.Get("Authorization")) > 0 || len(c.request.Header.Get("Cookie")) > 0
+		if _, ok := requestDirectives["no-cache"]; !ok {
+			if entry := rc.get(key); entry != nil && (entry.shared || !isAuthenticated) && entry.variant == generateVariant(c, entry.vary) {
+				header := c.response.Header()
+				for name, values := range entry.header {
+					header[name] = values
+				}
+				header.Set("Age", strconv.Itoa(int(time.Since(entry.createdAt).Seconds())))
+				header.Set("X-Cache", "HIT")
+
+				c.writeBody(util.Status200(), entry.header.Get("Content-Type"), entry.body)
+				return
+			}
+		}
+
+		// Record response
+		recorder := &responseRecorder{ResponseWriter: c.response}
+		c.response.Header().Set("X-Cache", "MISS")
+		c.response = recorder
+		defer func() { c.response = recorder.ResponseWriter }()
+		f(c)
+
+		/* Condition validation: only cache complete response */
+		if recorder.status != http.StatusOK || recorder.streaming || len(recorder.Header().Get("Set-Cookie")) > 0 {
+			return
+		}
+		vary := splitHeaderList(recorder.Header().Values("Vary"))
+		if containsToken(vary, "*") {
+			return
+		}
+
+		ttl := rc.ttl
+		responseDirectives := parseCacheControl(recorder.Header().Get("Cache-Control"))
+		for _, directive := range []string{"no-store", "no-cache", "private"} {
+			if _, ok := responseDirectives[directive]; ok {
+				return
+			}
+		}
+		_, isPublic := responseDirectives["public"]
+		sharedMaxAge, isShared := responseDirectives["s-maxage"]
+		if isShared {
+			ttl = sharedMaxAge
+		} else if maxAge, ok := responseDirectives["max-age"]; ok {
+			ttl = maxAge
+		}
+		if ttl <= 0 {
+			return
+		}
+
+		/* Condition validation: authenticated response might be personalized */
+		if isAuthenticated && !isPublic && !isShared {
+			return
+		}
+
+		// Persist response
+		header := make(http.Header)
+		for name, values := range recorder.Header() {
+			if name != "X-Cache" {
+				header[name] = values
+			}
+		}
+
+		var tags []string
+		for _, tag := range strings.Split(header.Get(CacheTagHeader), ",") {
+			if tag = strings.TrimSpace(tag); len(tag) > 0 {
+				tags = append(tags, tag)
+			}
+		}
+
+		now := time.Now()
+		rc.set(&cacheEntry{
+			key:       key,
+			url:       generateURL(c),
+			tags:      tags,
+			header:    header,
+			body:      recorder.body.Bytes(),
+			vary:      vary,
+			variant:   generateVariant(c, vary),
+			shared:    isPublic || isShared,
+			createdAt: now,
+			expiredAt: now.Add(ttl),
+		})
+	}
+}
+
+// Purge invalidates cached responses of request's URL, it should be bound with PURGE method. Purge is
+// an admin operation, it must be protected by an authentication adapter since anyone who can reach it
+// can flush the cache.
+//
+// Example:
+// server.BindPurge("/**", server.Adapt(cache.Purge, server.BasicAuthFile("admin", ".htpasswd")))
+func (rc *ResponseCache) Purge(c *RequestContext) {
+	count := rc.PurgeURL(generateURL(c))
+	c.OutputJSON(util.Status200(), map[string]int{"purged": count})
+}
+
+// PurgeURL invalidates cached responses of an URL.
+//
+// @param
+// - url {string} (the path & query of cached responses)
+//
+// @return
+// - count {int} (number of invalidated responses)
+func (rc *ResponseCache) PurgeURL(url string) int {
+	return rc.purge(func(entry *cacheEntry) bool {
+		return entry.url == url
+	})
+}
+
+// PurgeTag invalidates cached responses that had been tagged.
+//
+// @param
+// - tag {string} (the tag of cached responses)
+//
+// @return
+// - count {int} (number of invalidated responses)
+func (rc *ResponseCache) PurgeTag(tag string) int {
+	return rc.purge(func(entry *cacheEntry) bool {
+		for _, t := range entry.tags {
+			if t == tag {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// get returns cached response by key if it is still fresh.
+func (rc *ResponseCache) get(key string) *cacheEntry {
+	rc.mutex.Lock()
+	defer rc.mutex.Unlock()
+
+	element := rc.entries[key]
+	if element == nil {
+		return nil
+	}
+
+	entry := element.Value.(*cacheEntry)
+	if time.Now().After(entry.expiredAt) {
+		rc.lru.Remove(element)
+		delete(rc.entries, key)
+		return nil
+	}
+	rc.lru.MoveToFront(element)
+	return entry
+}
+
+// set persists response, the least recently used response will be evicted if cache is full.
+func (rc *ResponseCache) set(entry *cacheEntry) {
+	rc.mutex.Lock()
+	defer rc.mutex.Unlock()
+
+	if element := rc.entries[entry.key]; element != nil {
+		element.Value = entry
+		rc.lru.MoveToFront(element)
+		return
+	}
+	rc.entries[entry.key] = rc.lru.PushFront(entry)
+
+	for rc.lru.Len() > rc.capacity {
+		element := rc.lru.Back()
+		rc.lru.Remove(element)
+		delete(rc.entries, element.Value.(*cacheEntry).key)
+	}
+}
+
+// purge removes all responses that match condition.
+func (rc *ResponseCache) purge(match func(entry *cacheEntry) bool) (count int) {
+	rc.mutex.Lock()
+	defer rc.mutex.Unlock()
+
+	for element := rc.lru.Front(); element != nil; {
+		next := element.Next()
+		if entry := element.Value.(*cacheEntry); match(entry) {
+			rc.lru.Remove(element)
+			delete(rc.entries, entry.key)
+			count++
+		}
+		element = next
+	}
+	return
+}
+
+// generateKey generates cache's key from method, URL & vary headers.
+func (rc *ResponseCache) generateKey(c *RequestContext) string {
+	var buffer bytes.Buffer
+	buffer.WriteString(c.Method)
+	buffer.WriteString(" ")
+	buffer.WriteString(generateURL(c))
+
+	for _, name := range rc.varyHeaders {
+		buffer.WriteString("\n")
+		buffer.WriteString(strings.ToLower(name))
+		buffer.WriteString(": ")
+		buffer.WriteString(c.request.Header.Get(name))
+	}
+	return buffer.String()
+}
+
+// generateVariant generates request's values of headers that response varies on.
+func generateVariant(c *RequestContext, vary []string) string {
+	var buffer bytes.Buffer
+	for _, name := range vary {
+		buffer.WriteString(strings.ToLower(name))
+		buffer.WriteString(": ")
+		buffer.WriteString(strings.Join(c.request.Header.Values(name), ", "))
+		buffer.WriteString("\n")
+	}
+	return buffer.String()
+}
+
+// generateURL generates normalized URL from path & sorted query.
+func generateURL(c *RequestContext) string {
+	if query := c.request.URL.Query(); len(query) > 0 {
+		return c.Path + "?" + query.Encode()
+	}
+	return c.Path
+}
+
+// parseCacheControl parses Cache-Control header into directives.
+//
+// @param
+// - value {string} (Cache-Control header's value)
+//
+// @return
+// - directives {map[string]time.Duration} (directive -> duration, zero if directive has no duration)
+func parseCacheControl(value string) map[string]time.Duration {
+	directives := make(map[string]time.Duration)
+	for _, directive := range strings.Split(value, ",") {
+		directive = strings.ToLower(strings.TrimSpace(directive))
+		if len(directive) == 0 {
+			continue
+		}
+
+		tokens := strings.SplitN(directive, "=", 2)
+		var duration time.Duration
+		if len(tokens) == 2 {
+			if seconds, err := strconv.Atoi(strings.Trim(tokens[1], "\"")); err == nil {
+				duration = time.Duration(seconds) * time.Second
+			}
+		}
+		directives[tokens[0]] = duration
+	}
+	return directives
+}
@@ -0,0 +1,204 @@
+package server
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/phuc0302/go-server/expected_format"
+	"github.com/phuc0302/go-server/util"
+)
+
+func Test_ResponseCache(t *testing.T) {
+	defer os.Remove(Debug)
+	Initialize(true)
+
+	// Setup test server
+	counter := 0
+	cache := NewResponseCache(2, time.Minute, "Accept-Language")
+
+	BindGet("/items", Adapt(func(c *RequestContext) {
+		counter++
+		c.OutputHeader(CacheTagHeader, "items")
+		c.OutputText(util.Status200(), fmt.Sprintf("%d", counter))
+	}, cache.Adapter))
+	BindGet("/private", Adapt(func(c *RequestContext) {
+		counter++
+		c.OutputHeader("Cache-Control", "private")
+		c.OutputText(util.Status200(), fmt.Sprintf("%d", counter))
+	}, cache.Adapter))
+	BindPurge("/**", cache.Purge)
+
+	ts := httptest.NewServer(ServeHTTP())
+	defer ts.Close()
+
+	get := func(path string, language string) (string, string) {
+		request, _ := http.NewRequest("GET", ts.URL+path, nil)
+		request.Header.Set("Accept-Language", language)
+
+		response, _ := http.DefaultClient.Do(request)
+		bytes, _ := ioutil.ReadAll(response.Body)
+		return string(bytes), response.Header.Get("X-Cache")
+	}
+
+	// [Test 1] Cache miss then hit
+	if body, state := get("/items?b=2&a=1", "en"); body != "1" || state != "MISS" {
+		t.Errorf(expectedFormat.StringButFoundString, "1 MISS", body+" "+state)
+	}
+	if body, state := get("/items?a=1&b=2", "en"); body != "1" || state != "HIT" {
+		t.Errorf(expectedFormat.StringButFoundString, "1 HIT", body+" "+state)
+	}
+
+	// [Test 2] Vary header
+	if body, _ := get("/items?a=1&b=2", "vi"); body != "2" {
+		t.Errorf(expectedFormat.StringButFoundString, "2", body)
+	}
+
+	// [Test 3] Private response is never cached
+	get("/private", "en")
+	if body, _ := get("/private", "en"); body != "4" {
+		t.Errorf(expectedFormat.StringButFoundString, "4", body)
+	}
+
+	// [Test 4] Purge by URL
+	request, _ := http.NewRequest("PURGE", ts.URL+"/items?a=1&b=2", nil)
+	response, _ := http.DefaultClient.Do(request)
+	bytes, _ := ioutil.ReadAll(response.Body)
+	if string(bytes) != "{\"purged\":2}" {
+		t.Errorf(expectedFormat.StringButFoundString, "{\"purged\":2}", string(bytes))
+	}
+	if body, _ := get("/items?a=1&b=2", "en"); body != "5" {
+		t.Errorf(expectedFormat.StringButFoundString, "5", body)
+	}
+
+	// [Test 5] Purge by tag
+	if count := cache.PurgeTag("items"); count != 1 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 1, count)
+	}
+}
+
+func Test_ResponseCache_Eviction(t *testing.T) {
+	cache := NewResponseCache(2, time.Minute)
+	handler := Adapt(func(c *RequestContext) {
+		c.OutputText(util.Status200(), c.Path)
+	}, cache.Adapter)
+
+	// Create test server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler(CreateContext(w, r))
+	}))
+	defer ts.Close()
+
+	http.Get(ts.URL + "/1")
+	http.Get(ts.URL + "/2")
+	http.Get(ts.URL + "/1")
+	http.Get(ts.URL + "/3")
+
+	if cache.lru.Len() != 2 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 2, cache.lru.Len())
+	}
+	if cache.entries["get /2"] != nil {
+		t.Error(expectedFormat.Nil)
+	}
+	if cache.entries["get /1"] == nil {
+		t.Error(expectedFormat.NotNil)
+	}
+}
+
+func Test_ResponseCache_Authenticated(t *testing.T) {
+	cache := NewResponseCache(4, time.Minute)
+	handler := Adapt(func(c *RequestContext) {
+		if c.Path == "/public" {
+			c.OutputHeader("Cache-Control", "public, max-age=60")
+		}
+		c.OutputText(util.Status200(), c.request.Header.Get("Authorization")+c.request.Header.Get("Cookie"))
+	}, cache.Adapter)
+
+	// Create test server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler(CreateContext(w, r))
+	}))
+	defer ts.Close()
+
+	get := func(path string, name string, value string) string {
+		request, _ := http.NewRequest("GET", ts.URL+path, nil)
+		if len(name) > 0 {
+			request.Header.Set(name, value)
+		}
+
+		response, _ := http.DefaultClient.Do(request)
+		bytes, _ := ioutil.ReadAll(response.Body)
+		return string(bytes)
+	}
+
+	// [Test 1] Authenticated response is not shared with other users
+	get("/profile", "Authorization", "Bearer alice")
+	if body := get("/profile", "Authorization", "Bearer bob"); body != "Bearer bob" {
+		t.Errorf(expectedFormat.StringButFoundString, "Bearer bob", body)
+	}
+	get("/profile", "Cookie", "session=alice")
+	if body := get("/profile", "Cookie", "session=bob"); body != "session=bob" {
+		t.Errorf(expectedFormat.StringButFoundString, "session=bob", body)
+	}
+
+	// [Test 2] Anonymous response is not served to authenticated request
+	get("/profile", "", "")
+	if body := get("/profile", "Authorization", "Bearer bob"); body != "Bearer bob" {
+		t.Errorf(expectedFormat.StringButFoundString, "Bearer bob", body)
+	}
+
+	// [Test 3] Explicitly public response is shared
+	get("/public", "Authorization", "Bearer alice")
+	if body := get("/public", "Authorization", "Bearer bob"); body != "Bearer alice" {
+		t.Errorf(expectedFormat.StringButFoundString, "Bearer alice", body)
+	}
+}
+
+func Test_ResponseCache_Vary(t *testing.T) {
+	defer os.Remove(Debug)
+	Initialize(true)
+
+	cache := NewResponseCache(4, time.Minute)
+	handler := Adapt(func(c *RequestContext) {
+		if c.Path == "/events" {
+			stream, _ := c.SSE()
+			stream.Send("", "", "sample")
+			return
+		}
+		c.Output(util.Status200(), "sample")
+	}, cache.Adapter)
+
+	// Create test server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler(CreateContext(w, r))
+	}))
+	defer ts.Close()
+
+	get := func(path string, accept string) *http.Response {
+		request, _ := http.NewRequest("GET", ts.URL+path, nil)
+		request.Header.Set("Accept", accept)
+
+		response, _ := http.DefaultClient.Do(request)
+		ioutil.ReadAll(response.Body)
+		return response
+	}
+
+	// [Test 1] Response is only served to requests with the same Accept
+	get("/sample", "application/json")
+	if response := get("/sample", "text/plain"); response.Header.Get("Content-Type") != "text/plain" {
+		t.Errorf(expectedFormat.StringButFoundString, "text/plain", response.Header.Get("Content-Type"))
+	}
+	if response := get("/sample", "text/plain"); response.Header.Get("X-Cache") != "HIT" {
+		t.Errorf(expectedFormat.StringButFoundString, "HIT", response.Header.Get("X-Cache"))
+	}
+
+	// [Test 2] Event stream is never cached
+	get("/events", "text/event-stream")
+	if response := get("/events", "text/event-stream"); response.Header.Get("X-Cache") != "MISS" {
+		t.Errorf(expectedFormat.StringButFoundString, "MISS", response.Header.Get("X-Cache"))
+	}
+}
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"bytes"
+	"mime"
+	"net/http"
+)
+
+// responseRecorder describes a response writer that keeps a copy of response while writing it to
+// client. Event streams are never recorded since they have no end.
+type responseRecorder struct {
+	http.ResponseWriter
+
+	status    int
+	body      bytes.Buffer
+	streaming bool
+}
+
+// WriteHeader records status code before sending it.
+func (r *responseRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+		r.streaming = isEventStream(r.Header().Get("Content-Type"))
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Write records data before sending it.
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+		r.streaming = isEventStream(r.Header().Get("Content-Type"))
+	}
+	if !r.streaming {
+		r.body.Write(data)
+	}
+	return r.ResponseWriter.Write(data)
+}
+
+// Flush sends buffered data to client if underline writer supports it.
+func (r *responseRecorder) Flush() {
+	r.FlushError()
+}
+
+// FlushError sends buffered data to client through every wrapped writer, status code is recorded as
+// 200 if flushing succeeds before any write, just like net/http sends it.
+func (r *responseRecorder) FlushError() error {
+	if err := http.NewResponseController(r.ResponseWriter).Flush(); err != nil {
+		return err
+	}
+
+	if r.status == 0 {
+		r.status = http.StatusOK
+		r.streaming = isEventStream(r.Header().Get("Content-Type"))
+	}
+	return nil
+}
+
+// Unwrap returns underline response writer.
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// isEventStream checks if content type is server-sent events' stream.
+func isEventStream(contentType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	return mediaType == "text/event-stream"
+}
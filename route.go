@@ -4,8 +4,9 @@ import "regexp"
 
 // Route describes a route component implementation.
 type Route struct {
-	regex    *regexp.Regexp
-	handlers map[string]HandleContextFunc
+	regex      *regexp.Regexp
+	patternURL string
+	handlers   map[string]HandleContextFunc
 }
 
 // DefaultRoute creates new route component.
@@ -11,8 +11,9 @@ import (
 
 // Router describes a router component implementation.
 type Router struct {
-	groups []string
-	routes []*Route
+	groups   []string
+	adapters [][]Adapter
+	routes   []*Route
 }
 
 // GroupRoute generates path's prefix for following URLs.
@@ -20,9 +21,12 @@ type Router struct {
 // @param
 // - prefixURI {string} (the prefix for url)
 // - handler {HandleGroupFunc} (the callback func)
-func (r *Router) GroupRoute(prefixURI string, handler HandleGroupFunc) {
+// - adapters {Adapter} (a list of adapter func that will decorate every handler within group)
+func (r *Router) GroupRoute(prefixURI string, handler HandleGroupFunc, adapters ...Adapter) {
 	r.groups = append(r.groups, prefixURI)
+	r.adapters = append(r.adapters, adapters)
 	handler()
+	r.adapters = r.adapters[:len(r.adapters)-1]
 	r.groups = r.groups[:len(r.groups)-1]
 }
 
@@ -36,6 +40,15 @@ func (r *Router) BindRoute(method string, patternURL string, handler HandleConte
 	patternURL = r.mergeGroup(patternURL)
 	logrus.Infof("%-6s -> %s", strings.ToUpper(method), patternURL)
 
+	// Decorate handler with group's adapters, outer group's adapters will be executed first
+	if handler != nil {
+		var adapters []Adapter
+		for _, groupAdapters := range r.adapters {
+			adapters = append(adapters, groupAdapters...)
+		}
+		handler = Adapt(handler, adapters...)
+	}
+
 	// Define regex pattern
 	regexPattern := util.ConvertPath(patternURL)
 
@@ -47,6 +60,7 @@ func (r *Router) BindRoute(method string, patternURL string, handler HandleConte
 		}
 	}
 	newRoute := DefaultRoute(regexPattern)
+	newRoute.patternURL = patternURL
 	newRoute.BindHandler(method, handler)
 
 	// Append to current list
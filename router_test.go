@@ -165,3 +165,40 @@ func Test_MatchRoute_ValidHTTPMethodAndPath(t *testing.T) {
 	http.Get(fmt.Sprintf("%s/user/profile.htm/", ts.URL))
 	http.Get(fmt.Sprintf("%s/user/profile.html/?userID=1", ts.URL))
 }
+
+func Test_GroupRoute_Adapters(t *testing.T) {
+	var events []string
+	adapter := func(name string) Adapter {
+		return func(f HandleContextFunc) HandleContextFunc {
+			return func(c *RequestContext) {
+				events = append(events, name)
+				f(c)
+			}
+		}
+	}
+
+	// Setup router
+	router := new(Router)
+	router.GroupRoute("/api", func() {
+		router.GroupRoute("/v1", func() {
+			router.BindRoute(Get, "/sample", func(c *RequestContext) {
+				events = append(events, "handler")
+			})
+		}, adapter("inner"))
+	}, adapter("outer"))
+	router.BindRoute(Get, "/sample", func(c *RequestContext) {})
+
+	route, _ := router.MatchRoute(Get, "/api/v1/sample")
+	route.InvokeHandler(&RequestContext{Method: Get})
+	if strings.Join(events, ",") != "outer,inner,handler" {
+		t.Errorf(expectedFormat.StringButFoundString, "outer,inner,handler", strings.Join(events, ","))
+	}
+
+	// Adapters must not leak outside of group
+	events = nil
+	route, _ = router.MatchRoute(Get, "/sample")
+	route.InvokeHandler(&RequestContext{Method: Get})
+	if len(events) != 0 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 0, len(events))
+	}
+}
@@ -0,0 +1,113 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"strings"
+)
+
+// cspNonceKey is the extra's key that holds current Content-Security-Policy nonce.
+const cspNonceKey = "csp_nonce"
+
+// cspNoncePlaceholder will be replaced by a per-request nonce inside Content-Security-Policy.
+const cspNoncePlaceholder = "{nonce}"
+
+// securityHeaderDisabled instructs override to remove inherited header.
+const securityHeaderDisabled = "-"
+
+// SecurityHeaders describes a security response headers policy.
+type SecurityHeaders struct {
+	StrictTransportSecurity string `json:"strict_transport_security,omitempty"` // Only sent over TLS
+	ContentTypeOptions      string `json:"content_type_options,omitempty"`
+	FrameOptions            string `json:"frame_options,omitempty"`
+	ReferrerPolicy          string `json:"referrer_policy,omitempty"`
+	PermissionsPolicy       string `json:"permissions_policy,omitempty"`
+	ContentSecurityPolicy   string `json:"content_security_policy,omitempty"` // "{nonce}" will be replaced by per-request nonce
+}
+
+// merge returns new policy that takes override's values if there are any.
+//
+// @param
+// - override {SecurityHeaders} (the policy that will override current one, "-" will remove header)
+//
+// @return
+// - policy {SecurityHeaders} (the merged policy)
+func (s SecurityHeaders) merge(override SecurityHeaders) SecurityHeaders {
+	pick := func(value string, overrideValue string) string {
+		if len(overrideValue) > 0 {
+			return overrideValue
+		}
+		return value
+	}
+
+	return SecurityHeaders{
+		StrictTransportSecurity: pick(s.StrictTransportSecurity, override.StrictTransportSecurity),
+		ContentTypeOptions:      pick(s.ContentTypeOptions, override.ContentTypeOptions),
+		FrameOptions:            pick(s.FrameOptions, override.FrameOptions),
+		ReferrerPolicy:          pick(s.ReferrerPolicy, override.ReferrerPolicy),
+		PermissionsPolicy:       pick(s.PermissionsPolicy, override.PermissionsPolicy),
+		ContentSecurityPolicy:   pick(s.ContentSecurityPolicy, override.ContentSecurityPolicy),
+	}
+}
+
+// applySecurityHeaders writes security headers that had been configured for request's path.
+//
+// @param
+// - w {http.ResponseWriter} (the response writer)
+// - address {clientAddress} (the client's address)
+// - path {string} (request's cleaned path)
+//
+// @return
+// - nonce {string} (the Content-Security-Policy nonce, empty if policy does not require it)
+func applySecurityHeaders(w http.ResponseWriter, address *clientAddress, path string) (nonce string) {
+	policy := Cfg.SecurityHeaders
+
+	// Apply the longest matched group's override
+	matched := ""
+	for prefix := range Cfg.SecurityOverrides {
+		if hasPathPrefix(path, prefix) && len(prefix) > len(matched) {
+			matched = prefix
+		}
+	}
+	if len(matched) > 0 {
+		policy = policy.merge(Cfg.SecurityOverrides[matched])
+	}
+
+	// Generate nonce if necessary
+	csp := policy.ContentSecurityPolicy
+	if strings.Contains(csp, cspNoncePlaceholder) {
+		nonce = generateNonce()
+		csp = strings.Replace(csp, cspNoncePlaceholder, "'nonce-"+nonce+"'", -1)
+	}
+
+	header := w.Header()
+	setHeader := func(name string, value string) {
+		if len(value) > 0 && value != securityHeaderDisabled {
+			header.Set(name, value)
+		}
+	}
+
+	if address.scheme == "https" {
+		setHeader("Strict-Transport-Security", policy.StrictTransportSecurity)
+	}
+	setHeader("X-Content-Type-Options", policy.ContentTypeOptions)
+	setHeader("X-Frame-Options", policy.FrameOptions)
+	setHeader("Referrer-Policy", policy.ReferrerPolicy)
+	setHeader("Permissions-Policy", policy.PermissionsPolicy)
+	setHeader("Content-Security-Policy", csp)
+	return
+}
+
+// CSPNonce returns current Content-Security-Policy nonce, will be empty if policy does not require it.
+func (c *RequestContext) CSPNonce() string {
+	nonce, _ := c.GetExtra(cspNonceKey).(string)
+	return nonce
+}
+
+// generateNonce generates new random nonce.
+func generateNonce() string {
+	bytes := make([]byte, 16)
+	rand.Read(bytes)
+	return base64.StdEncoding.EncodeToString(bytes)
+}
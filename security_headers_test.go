@@ -0,0 +1,83 @@
+package server
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/phuc0302/go-server/expected_format"
+	"github.com/phuc0302/go-server/util"
+)
+
+func Test_SecurityHeaders(t *testing.T) {
+	defer os.Remove(Debug)
+	Initialize(true)
+
+	// Setup policy
+	Cfg.SecurityHeaders.ContentSecurityPolicy = "script-src {nonce}"
+	Cfg.SecurityOverrides = map[string]SecurityHeaders{
+		"/api": {
+			FrameOptions:          "-",
+			ContentSecurityPolicy: "default-src 'none'",
+		},
+	}
+
+	// Setup test server
+	BindGet("/sample", func(c *RequestContext) {
+		c.OutputText(util.Status200(), c.CSPNonce())
+	})
+	BindGet("/api/sample", func(c *RequestContext) {
+		c.OutputText(util.Status200(), c.CSPNonce())
+	})
+	BindGet("/apidocs", func(c *RequestContext) {
+		c.OutputText(util.Status200(), c.CSPNonce())
+	})
+
+	ts := httptest.NewServer(ServeHTTP())
+	defer ts.Close()
+
+	// [Test 1] Global policy
+	response, _ := http.Get(fmt.Sprintf("%s/%s", ts.URL, "sample"))
+	nonce, _ := ioutil.ReadAll(response.Body)
+
+	if len(nonce) == 0 {
+		t.Error(expectedFormat.NotNil)
+	}
+	if csp := response.Header.Get("Content-Security-Policy"); csp != fmt.Sprintf("script-src 'nonce-%s'", nonce) {
+		t.Errorf(expectedFormat.StringButFoundString, fmt.Sprintf("script-src 'nonce-%s'", nonce), csp)
+	}
+	if header := response.Header.Get("X-Content-Type-Options"); header != "nosniff" {
+		t.Errorf(expectedFormat.StringButFoundString, "nosniff", header)
+	}
+	if header := response.Header.Get("X-Frame-Options"); header != "SAMEORIGIN" {
+		t.Errorf(expectedFormat.StringButFoundString, "SAMEORIGIN", header)
+	}
+	if header := response.Header.Get("Strict-Transport-Security"); len(header) > 0 {
+		t.Errorf(expectedFormat.StringButFoundString, "", header)
+	}
+
+	// [Test 2] Group's override
+	response, _ = http.Get(fmt.Sprintf("%s/%s", ts.URL, "api/sample"))
+	nonce, _ = ioutil.ReadAll(response.Body)
+
+	if len(nonce) > 0 {
+		t.Errorf(expectedFormat.StringButFoundString, "", nonce)
+	}
+	if csp := response.Header.Get("Content-Security-Policy"); csp != "default-src 'none'" {
+		t.Errorf(expectedFormat.StringButFoundString, "default-src 'none'", csp)
+	}
+	if header := response.Header.Get("X-Frame-Options"); len(header) > 0 {
+		t.Errorf(expectedFormat.StringButFoundString, "", header)
+	}
+	if header := response.Header.Get("Referrer-Policy"); header != "strict-origin-when-cross-origin" {
+		t.Errorf(expectedFormat.StringButFoundString, "strict-origin-when-cross-origin", header)
+	}
+	// [Test 3] Override matches on path segment boundary
+	response, _ = http.Get(fmt.Sprintf("%s/%s", ts.URL, "apidocs"))
+	if header := response.Header.Get("X-Frame-Options"); header != "SAMEORIGIN" {
+		t.Errorf(expectedFormat.StringButFoundString, "SAMEORIGIN", header)
+	}
+}
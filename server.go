@@ -6,6 +6,7 @@ import (
 	"os"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/julienschmidt/httprouter"
@@ -117,8 +118,9 @@ func RunTLS(certFile string, keyFile string) {
 // @param
 // - prefixURI {string} (the prefix for url)
 // - handler {HandleGroupFunc} (the callback func)
-func GroupRoute(prefixURI string, handler HandleGroupFunc) {
-	router.GroupRoute(prefixURI, handler)
+// - adapters {Adapter} (a list of adapter func that will decorate every handler within group)
+func GroupRoute(prefixURI string, handler HandleGroupFunc, adapters ...Adapter) {
+	router.GroupRoute(prefixURI, handler, adapters...)
 }
 
 // BindCopy routes copy request to registered handler.
@@ -240,10 +242,35 @@ func generateAddress() (address string) {
 func ServeHTTP() http.Handler {
 	methodsValidation := regexp.MustCompile(fmt.Sprintf("^(%s)$", strings.Join(Cfg.AllowMethods, "|")))
 
+	var limiter *Limiter
+	if Cfg.MaxInflight > 0 {
+		limiter = NewLimiter(Cfg.MaxInflight, Cfg.QueueSize, Cfg.QueueTimeout).PerClient(Cfg.MaxInflightPerClient)
+	}
+
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer Recovery(w, r)
+		address := resolveClientAddress(r)
+
+		/* Condition validation: shed load if server is busy */
+		if limiter != nil {
+			if !limiter.acquire(address.ip) {
+				w.Header().Set("Retry-After", limiter.retryAfter())
+				panic(util.Status503WithDescription("Server is busy."))
+			}
+
+			start := time.Now()
+			defer func() { limiter.release(address.ip, time.Since(start)) }()
+		}
+
 		method := strings.ToLower(r.Method)
 		path := httprouter.CleanPath(r.URL.Path)
+		nonce := applySecurityHeaders(w, address, path)
+
+		/* Condition validation: validate client's address */
+		if !isAccessAllowed(path, address.ip) {
+			panic(util.Status403())
+		}
+		checkMaintenance(w, r, path, address.ip)
 
 		/* Condition validation: validate request method */
 		if !methodsValidation.MatchString(method) {
@@ -252,10 +279,17 @@ func ServeHTTP() http.Handler {
 
 		// Find route to handle request
 		if route, pathParams := router.MatchRoute(method, path); route != nil {
+			limitBody(w, r, path)
+
 			context := CreateContext(w, r)
+			context.address = address
+			context.route = route.patternURL
 			if pathParams != nil {
 				context.PathParams = pathParams
 			}
+			if len(nonce) > 0 {
+				context.SetExtra(cspNonceKey, nonce)
+			}
 			route.InvokeHandler(context)
 		} else {
 			if len(Cfg.StaticFolders) > 0 && method == Get {
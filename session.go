@@ -0,0 +1,282 @@
+package server
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// sessionKey is the extra's key that holds current session.
+const sessionKey = "session"
+
+// Session describes a server-side session that lives across requests.
+// Values will be JSON encoded by cookie & file stores, thus numbers will be decoded as float64.
+type Session struct {
+	ID         string                 `json:"id"`
+	Values     map[string]interface{} `json:"values,omitempty"`
+	Flash      []string               `json:"flashes,omitempty"`
+	CreatedAt  time.Time              `json:"created_at"`
+	AccessedAt time.Time              `json:"accessed_at"`
+
+	previousID string
+	isNew      bool
+	modified   bool
+	destroyed  bool
+	mutex      sync.Mutex
+}
+
+// SessionOptions describes session's cookie & timeouts.
+type SessionOptions struct {
+	CookieName      string        // Default is "session_id"
+	Path            string        // Default is "/"
+	Domain          string        //
+	Secure          bool          //
+	IdleTimeout     time.Duration // 0 means session never idles out
+	AbsoluteTimeout time.Duration // 0 means session lives until browser is closed
+}
+
+// Sessions generates adapter that loads session before handler & persists it before response is written.
+//
+// @param
+// - store {SessionStore} (the storage for sessions)
+// - options {SessionOptions} (the session's cookie & timeouts)
+//
+// @return
+// - adapter {Adapter} (the session adapter)
+func Sessions(store SessionStore, options SessionOptions) Adapter {
+	/* Condition validation: validate store */
+	if store == nil {
+		panic("Session store must not be nil.")
+	}
+	if len(options.CookieName) == 0 {
+		options.CookieName = "session_id"
+	}
+	if len(options.Path) == 0 {
+		options.Path = "/"
+	}
+
+	return func(f HandleContextFunc) HandleContextFunc {
+		return func(c *RequestContext) {
+			now := time.Now()
+
+			var session *Session
+			if cookie, err := c.request.Cookie(options.CookieName); err == nil && len(cookie.Value) > 0 {
+				if session, err = store.Load(cookie.Value); err != nil {
+					session = nil
+				}
+			}
+
+			// Expire session if necessary
+			if session != nil && ((options.IdleTimeout > 0 && now.Sub(session.AccessedAt) > options.IdleTimeout) ||
+				(options.AbsoluteTimeout > 0 && now.Sub(session.CreatedAt) > options.AbsoluteTimeout)) {
+				store.Destroy(session.ID)
+				session = nil
+			}
+			if session != nil && session.Values == nil {
+				session.Values = make(map[string]interface{})
+			}
+			if session == nil {
+				session = &Session{
+					ID:        generateSessionID(),
+					Values:    make(map[string]interface{}),
+					CreatedAt: now,
+					isNew:     true,
+				}
+			}
+			c.SetExtra(sessionKey, session)
+
+			// Persist session right before response is written, new session is persisted only if it is modified
+			writer := &sessionWriter{ResponseWriter: c.response}
+			writer.commit = func() {
+				commitSession(writer, store, options, session)
+			}
+			c.response = writer
+			defer func() {
+				c.response = writer.ResponseWriter
+				writer.commitOnce()
+			}()
+			f(c)
+		}
+	}
+}
+
+// Session returns current session, will be nil if session adapter is not in used.
+func (c *RequestContext) Session() *Session {
+	session, _ := c.GetExtra(sessionKey).(*Session)
+	return session
+}
+
+// Get returns value that had been associated with key.
+func (s *Session) Get(key string) interface{} {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.Values[key]
+}
+
+// Set associates value with key.
+func (s *Session) Set(key string, value interface{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.Values[key] = value
+	s.modified = true
+}
+
+// Delete removes value that had been associated with key.
+func (s *Session) Delete(key string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.Values, key)
+	s.modified = true
+}
+
+// Rotate generates new session's ID while keeping values, it should be called on login to prevent
+// session fixation.
+func (s *Session) Rotate() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if len(s.previousID) == 0 {
+		s.previousID = s.ID
+	}
+	s.ID = generateSessionID()
+	s.modified = true
+}
+
+// Destroy removes session from store & client, it should be called on logout.
+func (s *Session) Destroy() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.Values = make(map[string]interface{})
+	s.Flash = nil
+	s.destroyed = true
+}
+
+// AddFlash adds a message that will be available to the next request only.
+func (s *Session) AddFlash(message string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.Flash = append(s.Flash, message)
+	s.modified = true
+}
+
+// Flashes returns & clears flash messages.
+func (s *Session) Flashes() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	flashes := s.Flash
+	if len(flashes) > 0 {
+		s.Flash = nil
+		s.modified = true
+	}
+	return flashes
+}
+
+// sessionWriter describes a response writer that persists session before the first write.
+type sessionWriter struct {
+	http.ResponseWriter
+
+	commit    func()
+	committed bool
+}
+
+// WriteHeader persists session before sending status code.
+func (w *sessionWriter) WriteHeader(status int) {
+	w.commitOnce()
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write persists session before sending data.
+func (w *sessionWriter) Write(data []byte) (int, error) {
+	w.commitOnce()
+	return w.ResponseWriter.Write(data)
+}
+
+// Flush persists session before flushing buffered data to client.
+func (w *sessionWriter) Flush() {
+	w.FlushError()
+}
+
+// FlushError persists session before flushing buffered data through every wrapped writer.
+func (w *sessionWriter) FlushError() error {
+	w.commitOnce()
+	return http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Hijack persists session before taking over connection, session's cookie is sent along with
+// WebSocket handshake.
+func (w *sessionWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.commitOnce()
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+// Unwrap returns underline response writer.
+func (w *sessionWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// commitOnce persists session if it is not yet persisted.
+func (w *sessionWriter) commitOnce() {
+	if !w.committed {
+		w.committed = true
+		w.commit()
+	}
+}
+
+// commitSession persists session to store & sends session's cookie to client.
+func commitSession(w http.ResponseWriter, store SessionStore, options SessionOptions, session *Session) {
+	session.mutex.Lock()
+	defer session.mutex.Unlock()
+
+	/* Condition validation: new session that is not modified must not be persisted */
+	if session.isNew && (!session.modified || session.destroyed) {
+		return
+	}
+
+	cookie := &http.Cookie{
+		Name:     options.CookieName,
+		Path:     options.Path,
+		Domain:   options.Domain,
+		Secure:   options.Secure,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}
+
+	if len(session.previousID) > 0 {
+		store.Destroy(session.previousID)
+		session.previousID = ""
+	}
+	if session.destroyed {
+		store.Destroy(session.ID)
+		cookie.MaxAge = -1
+		http.SetCookie(w, cookie)
+		return
+	}
+
+	session.AccessedAt = time.Now()
+	token, err := store.Save(session)
+	if err != nil {
+		logrus.Warningf("could not save session: %s", err)
+		return
+	}
+
+	cookie.Value = token
+	if options.AbsoluteTimeout > 0 {
+		cookie.Expires = session.CreatedAt.Add(options.AbsoluteTimeout)
+	}
+	http.SetCookie(w, cookie)
+}
+
+// generateSessionID generates new random session's ID.
+func generateSessionID() string {
+	bytes := make([]byte, 32)
+	rand.Read(bytes)
+	return hex.EncodeToString(bytes)
+}
@@ -0,0 +1,302 @@
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/phuc0302/go-server/util"
+)
+
+// maxCookieSize is the maximum size of cookie's value that browsers accept.
+const maxCookieSize = 4096
+
+// sweepInterval is the minimum duration between two expired sessions' sweeps.
+const sweepInterval = time.Minute
+
+// sessionIDValidation validates session's ID before it is used as file's name.
+var sessionIDValidation = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// SessionStore describes a storage for sessions.
+type SessionStore interface {
+	// Load returns session that is identified by cookie's token.
+	Load(token string) (*Session, error)
+
+	// Save persists session & returns the token that will be sent as cookie's value.
+	Save(session *Session) (token string, err error)
+
+	// Destroy removes session by ID.
+	Destroy(id string) error
+}
+
+// memorySessionStore describes an in-memory SessionStore.
+type memorySessionStore struct {
+	maxAge    time.Duration
+	sessions  map[string]*Session
+	lastSweep time.Time
+	mutex     sync.Mutex
+}
+
+// NewMemorySessionStore creates new in-memory session store.
+//
+// @param
+// - maxAge {time.Duration} (sessions that are not accessed within maxAge will be removed)
+//
+// @return
+// - store {SessionStore} (an in-memory store's new instance)
+func NewMemorySessionStore(maxAge time.Duration) SessionStore {
+	return &memorySessionStore{
+		maxAge:    maxAge,
+		sessions:  make(map[string]*Session),
+		lastSweep: time.Now(),
+	}
+}
+
+// Load returns session that is identified by cookie's token.
+func (s *memorySessionStore) Load(token string) (*Session, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	session := s.sessions[token]
+	if session == nil {
+		return nil, fmt.Errorf("Session is not found.")
+	}
+	return copySession(session), nil
+}
+
+// Save persists session & returns the token that will be sent as cookie's value.
+func (s *memorySessionStore) Save(session *Session) (string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.sweep(time.Now())
+	s.sessions[session.ID] = copySession(session)
+	return session.ID, nil
+}
+
+// Destroy removes session by ID.
+func (s *memorySessionStore) Destroy(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.sessions, id)
+	return nil
+}
+
+// sweep removes expired sessions, at most once per sweepInterval.
+func (s *memorySessionStore) sweep(now time.Time) {
+	if s.maxAge <= 0 || now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for id, session := range s.sessions {
+		if now.Sub(session.AccessedAt) > s.maxAge {
+			delete(s.sessions, id)
+		}
+	}
+}
+
+// fileSessionStore describes a SessionStore that persists each session as a JSON file.
+type fileSessionStore struct {
+	dirPath   string
+	maxAge    time.Duration
+	lastSweep time.Time
+	mutex     sync.Mutex
+}
+
+// NewFileSessionStore creates new file-based session store.
+//
+// @param
+// - dirPath {string} (the directory that will hold session's files)
+// - maxAge {time.Duration} (sessions that are not accessed within maxAge will be rejected & removed)
+//
+// @return
+// - store {SessionStore} (a file-based store's new instance)
+func NewFileSessionStore(dirPath string, maxAge time.Duration) SessionStore {
+	if !util.DirExisted(dirPath) {
+		util.CreateDir(dirPath, 0700)
+	}
+	return &fileSessionStore{
+		dirPath:   dirPath,
+		maxAge:    maxAge,
+		lastSweep: time.Now(),
+	}
+}
+
+// Load returns session that is identified by cookie's token.
+func (s *fileSessionStore) Load(token string) (*Session, error) {
+	/* Condition validation: validate token before accessing file system */
+	if !sessionIDValidation.MatchString(token) {
+		return nil, fmt.Errorf("Invalid session's ID.")
+	}
+
+	data, err := ioutil.ReadFile(s.filePath(token))
+	if err != nil {
+		return nil, err
+	}
+
+	session := new(Session)
+	if err := json.Unmarshal(data, session); err != nil {
+		return nil, err
+	}
+	if s.maxAge > 0 && time.Since(session.AccessedAt) > s.maxAge {
+		os.Remove(s.filePath(token))
+		return nil, fmt.Errorf("Session had been expired.")
+	}
+	return session, nil
+}
+
+// Save persists session & returns the token that will be sent as cookie's value.
+func (s *fileSessionStore) Save(session *Session) (string, error) {
+	s.sweep(time.Now())
+	data, err := json.Marshal(session)
+	if err != nil {
+		return "", err
+	}
+
+	// Write to temporary file first so that reader never sees partial session
+	tempPath := s.filePath(session.ID) + ".tmp"
+	if err := ioutil.WriteFile(tempPath, data, 0600); err != nil {
+		return "", err
+	}
+	return session.ID, os.Rename(tempPath, s.filePath(session.ID))
+}
+
+// Destroy removes session by ID.
+func (s *fileSessionStore) Destroy(id string) error {
+	if !sessionIDValidation.MatchString(id) {
+		return fmt.Errorf("Invalid session's ID.")
+	}
+	return os.Remove(s.filePath(id))
+}
+
+// sweep removes session's files that had not been saved within maxAge, at most once per
+// sweepInterval. File's modification time is used so that sessions are not decoded.
+func (s *fileSessionStore) sweep(now time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.maxAge <= 0 || now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	files, err := ioutil.ReadDir(s.dirPath)
+	if err != nil {
+		return
+	}
+	for _, file := range files {
+		name := file.Name()
+		if id := strings.TrimSuffix(strings.TrimSuffix(name, ".tmp"), ".json"); !sessionIDValidation.MatchString(id) {
+			continue
+		}
+		if now.Sub(file.ModTime()) > s.maxAge {
+			os.Remove(filepath.Join(s.dirPath, name))
+		}
+	}
+}
+
+// filePath returns session's file path.
+func (s *fileSessionStore) filePath(id string) string {
+	return filepath.Join(s.dirPath, id+".json")
+}
+
+// cookieSessionStore describes a SessionStore that keeps whole session inside a signed cookie.
+type cookieSessionStore struct {
+	secret []byte
+}
+
+// NewCookieSessionStore creates new signed cookie session store. Session's data is signed but not
+// encrypted, thus it should not hold secrets. Since there is no server-side state, Destroy only
+// expires client's cookie: a copied token stays valid until IdleTimeout or AbsoluteTimeout elapses,
+// use memory or file store if logout must revoke session.
+//
+// @param
+// - secret {[]byte} (the HMAC-SHA256 key, should be at least 32 bytes)
+//
+// @return
+// - store {SessionStore} (a cookie store's new instance)
+func NewCookieSessionStore(secret []byte) SessionStore {
+	/* Condition validation: validate secret */
+	if len(secret) == 0 {
+		panic("Session's secret must not be empty.")
+	}
+	return &cookieSessionStore{secret: secret}
+}
+
+// Load returns session that is identified by cookie's token.
+func (s *cookieSessionStore) Load(token string) (*Session, error) {
+	tokens := strings.Split(token, ".")
+	if len(tokens) != 2 {
+		return nil, fmt.Errorf("Invalid session's token.")
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(tokens[1])
+	if err != nil || !hmac.Equal(signature, s.sign(tokens[0])) {
+		return nil, fmt.Errorf("Invalid session's signature.")
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(tokens[0])
+	if err != nil {
+		return nil, err
+	}
+
+	session := new(Session)
+	if err := json.Unmarshal(data, session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// Save persists session & returns the token that will be sent as cookie's value.
+func (s *cookieSessionStore) Save(session *Session) (string, error) {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return "", err
+	}
+
+	payload := base64.RawURLEncoding.EncodeToString(data)
+	token := payload + "." + base64.RawURLEncoding.EncodeToString(s.sign(payload))
+	if len(token) > maxCookieSize {
+		return "", fmt.Errorf("Session is too large to be stored in cookie.")
+	}
+	return token, nil
+}
+
+// Destroy does nothing since session lives in client's cookie, issued tokens cannot be revoked.
+func (s *cookieSessionStore) Destroy(id string) error {
+	return nil
+}
+
+// sign generates HMAC-SHA256 signature of payload.
+func (s *cookieSessionStore) sign(payload string) []byte {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
+
+// copySession copies session's data without internal state.
+func copySession(session *Session) *Session {
+	values := make(map[string]interface{}, len(session.Values))
+	for key, value := range session.Values {
+		values[key] = value
+	}
+
+	return &Session{
+		ID:         session.ID,
+		Values:     values,
+		Flash:      append([]string(nil), session.Flash...),
+		CreatedAt:  session.CreatedAt,
+		AccessedAt: session.AccessedAt,
+	}
+}
@@ -0,0 +1,210 @@
+package server
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/phuc0302/go-server/expected_format"
+	"github.com/phuc0302/go-server/util"
+)
+
+func Test_Sessions(t *testing.T) {
+	defer os.Remove(Debug)
+	Initialize(true)
+
+	// Setup test server
+	store := NewMemorySessionStore(time.Hour)
+	GroupRoute("", func() {
+		BindPost("/visit", func(c *RequestContext) {
+			c.Session().Set("visited", true)
+			c.OutputText(util.Status200(), "")
+		})
+		BindPost("/login", func(c *RequestContext) {
+			session := c.Session()
+			session.Rotate()
+			session.Set("user", "admin")
+			session.AddFlash("Welcome!")
+			c.OutputText(util.Status200(), session.ID)
+		})
+		BindGet("/profile", func(c *RequestContext) {
+			session := c.Session()
+			c.OutputText(util.Status200(), fmt.Sprintf("%v %v", session.Get("user"), session.Flashes()))
+		})
+		BindPost("/logout", func(c *RequestContext) {
+			c.Session().Destroy()
+			c.OutputText(util.Status200(), "")
+		})
+	}, Sessions(store, SessionOptions{IdleTimeout: time.Hour}))
+
+	ts := httptest.NewServer(ServeHTTP())
+	defer ts.Close()
+
+	jar, _ := cookiejar.New(nil)
+	client := &http.Client{Jar: jar}
+	read := func(response *http.Response) string {
+		bytes, _ := ioutil.ReadAll(response.Body)
+		response.Body.Close()
+		return string(bytes)
+	}
+
+	// [Test 1] Anonymous session is persisted only when it is modified
+	response, _ := client.Get(ts.URL + "/profile")
+	read(response)
+	if cookies := response.Header["Set-Cookie"]; len(cookies) != 0 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 0, len(cookies))
+	}
+
+	response, _ = client.Post(ts.URL+"/visit", "application/x-www-form-urlencoded", nil)
+	read(response)
+	anonymousID := jar.Cookies(response.Request.URL)[0].Value
+
+	// [Test 2] Login rotates session's ID
+	response, _ = client.Post(ts.URL+"/login", "application/x-www-form-urlencoded", nil)
+	sessionID := read(response)
+	if sessionID == anonymousID {
+		t.Error("Expected session's ID had been rotated.")
+	}
+	if _, err := store.Load(anonymousID); err == nil {
+		t.Error("Expected previous session had been destroyed.")
+	}
+
+	// [Test 3] Values & flashes
+	response, _ = client.Get(ts.URL + "/profile")
+	if body := read(response); body != "admin [Welcome!]" {
+		t.Errorf(expectedFormat.StringButFoundString, "admin [Welcome!]", body)
+	}
+	response, _ = client.Get(ts.URL + "/profile")
+	if body := read(response); body != "admin []" {
+		t.Errorf(expectedFormat.StringButFoundString, "admin []", body)
+	}
+
+	// [Test 4] Logout
+	response, _ = client.Post(ts.URL+"/logout", "application/x-www-form-urlencoded", nil)
+	read(response)
+	if _, err := store.Load(sessionID); err == nil {
+		t.Error("Expected session had been destroyed.")
+	}
+	response, _ = client.Get(ts.URL + "/profile")
+	if body := read(response); body != "<nil> []" {
+		t.Errorf(expectedFormat.StringButFoundString, "<nil> []", body)
+	}
+}
+
+func Test_Sessions_IdleTimeout(t *testing.T) {
+	store := NewMemorySessionStore(time.Hour)
+	handler := Adapt(func(c *RequestContext) {
+		c.OutputText(util.Status200(), c.Session().ID)
+	}, Sessions(store, SessionOptions{IdleTimeout: time.Minute}))
+
+	// Create test server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler(CreateContext(w, r))
+	}))
+	defer ts.Close()
+
+	// Store an idle session
+	session := &Session{ID: generateSessionID(), AccessedAt: time.Now().Add(-time.Hour)}
+	store.Save(session)
+
+	request, _ := http.NewRequest("GET", ts.URL, nil)
+	request.AddCookie(&http.Cookie{Name: "session_id", Value: session.ID})
+	response, _ := http.DefaultClient.Do(request)
+	bytes, _ := ioutil.ReadAll(response.Body)
+
+	if string(bytes) == session.ID {
+		t.Error("Expected idle session had been replaced.")
+	}
+}
+
+func Test_CookieSessionStore(t *testing.T) {
+	store := NewCookieSessionStore([]byte("a-very-secret-key-for-testing-only"))
+	session := &Session{ID: generateSessionID(), Values: map[string]interface{}{"user": "admin"}}
+
+	token, err := store.Save(session)
+	if err != nil {
+		t.Error(expectedFormat.Nil)
+	}
+
+	// [Test 1] Valid token
+	if loaded, err := store.Load(token); err != nil || loaded.Values["user"] != "admin" {
+		t.Error("Expected session had been loaded.")
+	}
+
+	// [Test 2] Tampered token
+	if _, err := store.Load("e30" + token[3:]); err == nil {
+		t.Error(expectedFormat.NotNil)
+	}
+}
+
+func Test_FileSessionStore(t *testing.T) {
+	dirPath := "sessions"
+	defer os.RemoveAll(dirPath)
+
+	store := NewFileSessionStore(dirPath, time.Hour)
+	session := &Session{ID: generateSessionID(), Values: map[string]interface{}{"user": "admin"}, AccessedAt: time.Now()}
+
+	token, _ := store.Save(session)
+	if loaded, err := store.Load(token); err != nil || loaded.Values["user"] != "admin" {
+		t.Error("Expected session had been loaded.")
+	}
+
+	// Invalid ID must never reach file system
+	if _, err := store.Load("../" + token); err == nil {
+		t.Error(expectedFormat.NotNil)
+	}
+
+	store.Destroy(token)
+	if _, err := store.Load(token); err == nil {
+		t.Error(expectedFormat.NotNil)
+	}
+}
+
+func Test_MemorySessionStore_Sweep(t *testing.T) {
+	store := NewMemorySessionStore(time.Hour).(*memorySessionStore)
+	expired := &Session{ID: generateSessionID(), AccessedAt: time.Now().Add(-2 * time.Hour)}
+	store.Save(expired)
+
+	// Sweep is throttled
+	store.Save(&Session{ID: generateSessionID(), AccessedAt: time.Now()})
+	if len(store.sessions) != 2 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 2, len(store.sessions))
+	}
+
+	store.lastSweep = time.Now().Add(-2 * sweepInterval)
+	store.Save(&Session{ID: generateSessionID(), AccessedAt: time.Now()})
+	if _, err := store.Load(expired.ID); err == nil {
+		t.Error(expectedFormat.NotNil)
+	}
+	if len(store.sessions) != 2 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 2, len(store.sessions))
+	}
+}
+
+func Test_FileSessionStore_Sweep(t *testing.T) {
+	dirPath := "sessions"
+	defer os.RemoveAll(dirPath)
+
+	store := NewFileSessionStore(dirPath, time.Hour).(*fileSessionStore)
+	expired := &Session{ID: generateSessionID(), AccessedAt: time.Now().Add(-2 * time.Hour)}
+	store.Save(expired)
+
+	past := time.Now().Add(-2 * time.Hour)
+	os.Chtimes(store.filePath(expired.ID), past, past)
+	ioutil.WriteFile(store.filePath("unrelated"), []byte("{}"), 0600)
+	os.Chtimes(store.filePath("unrelated"), past, past)
+
+	store.lastSweep = time.Now().Add(-2 * sweepInterval)
+	store.Save(&Session{ID: generateSessionID(), AccessedAt: time.Now()})
+	if util.FileExisted(store.filePath(expired.ID)) {
+		t.Error("Expected expired session's file had been removed.")
+	}
+	if !util.FileExisted(store.filePath("unrelated")) {
+		t.Error("Expected unrelated file had been kept.")
+	}
+}
@@ -0,0 +1,198 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EventStream describes a Server-Sent Events stream.
+type EventStream struct {
+	context    *RequestContext
+	controller *http.ResponseController
+	stop       chan struct{}
+	stopOnce   sync.Once
+	mutex      sync.Mutex
+}
+
+// SSE starts a Server-Sent Events stream, response's header is sent immediately. Error will be
+// returned if response writer could not be flushed, e.g. when route is wrapped by Timeout adapter,
+// nothing is sent then & handler can still output another response.
+//
+// Example:
+//
+//	stream, err := c.SSE()
+//	if err != nil {
+//		return
+//	}
+//	defer stream.Close()
+//
+//	stream.Heartbeat(15 * time.Second)
+//	for message := range messages {
+//		if err := stream.Send("message", message.ID, message); err != nil {
+//			return
+//		}
+//	}
+//
+// @return
+// - stream {EventStream} (the event stream)
+// - err {error} (error during start process)
+func (c *RequestContext) SSE() (*EventStream, error) {
+	header := c.response.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("X-Accel-Buffering", "no")
+	header.Del("Content-Length")
+
+	stream := &EventStream{
+		context:    c,
+		controller: http.NewResponseController(c.response),
+		stop:       make(chan struct{}),
+	}
+
+	/* Condition validation: flush sends header with 200, it fails if writer buffers response */
+	stream.extendDeadline()
+	if err := stream.controller.Flush(); err != nil {
+		header.Del("Content-Type")
+		header.Del("Cache-Control")
+		header.Del("X-Accel-Buffering")
+		return nil, fmt.Errorf("could not flush event stream: %s", err)
+	}
+	return stream, nil
+}
+
+// LastEventID returns the ID of the last event that client had received before reconnecting.
+func (s *EventStream) LastEventID() string {
+	if id := s.context.request.Header.Get("Last-Event-ID"); len(id) > 0 {
+		return id
+	}
+	return s.context.request.URL.Query().Get("lastEventId")
+}
+
+// Done returns a channel that is closed when client disconnects.
+func (s *EventStream) Done() <-chan struct{} {
+	return s.context.Context().Done()
+}
+
+// Send sends an event, data will be JSON encoded unless it is string or []byte. Event & id can be
+// empty.
+//
+// @param
+// - event {string} (the event's name)
+// - id {string} (the event's ID, client will send it back as Last-Event-ID when reconnecting)
+// - data {interface} (the event's data)
+//
+// @return
+// - err {error} (error if client had disconnected or data could not be encoded)
+func (s *EventStream) Send(event string, id string, data interface{}) error {
+	var payload string
+	switch value := data.(type) {
+	case string:
+		payload = value
+	case []byte:
+		payload = string(value)
+	default:
+		bytes, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+		payload = string(bytes)
+	}
+
+	var buffer strings.Builder
+	if len(id) > 0 {
+		buffer.WriteString("id: " + sanitizeField(id) + "\n")
+	}
+	if len(event) > 0 {
+		buffer.WriteString("event: " + sanitizeField(event) + "\n")
+	}
+	for _, line := range strings.Split(lineBreaks.Replace(payload), "\n") {
+		buffer.WriteString("data: " + line + "\n")
+	}
+	buffer.WriteString("\n")
+
+	return s.write(buffer.String())
+}
+
+// Retry tells client how long it should wait before reconnecting.
+func (s *EventStream) Retry(duration time.Duration) error {
+	return s.write(fmt.Sprintf("retry: %d\n\n", duration/time.Millisecond))
+}
+
+// Heartbeat sends a comment every interval so that proxies keep connection open, it stops when client
+// disconnects or stream is closed.
+func (s *EventStream) Heartbeat(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if err := s.write(": heartbeat\n\n"); err != nil {
+					return
+				}
+			case <-s.stop:
+				return
+			case <-s.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Close stops heartbeat, it should be called before handler returns. Nothing will be written after
+// Close returns.
+func (s *EventStream) Close() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.stopOnce.Do(func() {
+		close(s.stop)
+	})
+}
+
+// write sends data & flushes it to client.
+func (s *EventStream) write(data string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	select {
+	case <-s.stop:
+		return errors.New("event stream had been closed")
+	case <-s.Done():
+		return s.context.Context().Err()
+	default:
+	}
+
+	s.extendDeadline()
+	if _, err := s.context.response.Write([]byte(data)); err != nil {
+		return err
+	}
+	return s.controller.Flush()
+}
+
+// extendDeadline pushes write deadline forward so that server's WriteTimeout applies to each write
+// instead of the whole stream.
+func (s *EventStream) extendDeadline() {
+	var deadline time.Time
+	if Cfg != nil && Cfg.WriteTimeout > 0 {
+		deadline = time.Now().Add(Cfg.WriteTimeout)
+	}
+	s.controller.SetWriteDeadline(deadline)
+}
+
+// lineBreaks normalizes CRLF & bare CR into LF, SSE treats all of them as end of line.
+var lineBreaks = strings.NewReplacer("\r\n", "\n", "\r", "\n")
+
+// fieldBreaks removes line breaks from single-line fields.
+var fieldBreaks = strings.NewReplacer("\r", "", "\n", "")
+
+// sanitizeField removes line breaks that would end event's field early.
+func sanitizeField(value string) string {
+	return fieldBreaks.Replace(value)
+}
@@ -0,0 +1,109 @@
+package server
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/phuc0302/go-server/expected_format"
+	"github.com/phuc0302/go-server/util"
+)
+
+func Test_SSE(t *testing.T) {
+	defer os.Remove(Debug)
+	Initialize(true)
+
+	// Setup test server
+	disconnected := make(chan struct{})
+	BindGet("/events", Adapt(func(c *RequestContext) {
+		stream, err := c.SSE()
+		if err != nil {
+			t.Error(expectedFormat.Nil)
+			return
+		}
+		defer stream.Close()
+
+		stream.Heartbeat(10 * time.Millisecond)
+		stream.Send("resume", "", stream.LastEventID())
+		stream.Send("", "2", map[string]string{"line": "a\nb"})
+		stream.Send("a\rb", "3\r\n", "x\ry\r\nz")
+
+		<-stream.Done()
+		close(disconnected)
+	}, Sessions(NewMemorySessionStore(time.Hour), SessionOptions{})))
+
+	ts := httptest.NewServer(ServeHTTP())
+	defer ts.Close()
+
+	request, _ := http.NewRequest("GET", ts.URL+"/events", nil)
+	request.Header.Set("Last-Event-ID", "1")
+	response, _ := http.DefaultClient.Do(request)
+
+	if contentType := response.Header.Get("Content-Type"); contentType != "text/event-stream" {
+		t.Errorf(expectedFormat.StringButFoundString, "text/event-stream", contentType)
+	}
+
+	// Read events while handler is still running
+	reader := bufio.NewReader(response.Body)
+	var lines []string
+	for len(lines) < 13 {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			break
+		}
+		lines = append(lines, strings.TrimRight(line, "\n"))
+	}
+
+	expected := "event: resume|data: 1||id: 2|data: {\"line\":\"a\\nb\"}||id: 3|event: ab|data: x|data: y|data: z||: heartbeat"
+	if found := strings.Join(lines, "|"); found != expected {
+		t.Errorf(expectedFormat.StringButFoundString, expected, found)
+	}
+
+	// Client disconnects
+	response.Body.Close()
+	select {
+	case <-disconnected:
+	case <-time.After(time.Second):
+		t.Error("Expected handler had detected client's disconnection.")
+	}
+}
+
+func Test_SSE_Timeout(t *testing.T) {
+	defer os.Remove(Debug)
+	Initialize(true)
+
+	// Setup test server
+	finished := make(chan struct{})
+	BindGet("/events", Adapt(func(c *RequestContext) {
+		defer close(finished)
+
+		stream, err := c.SSE()
+		if err == nil {
+			t.Error(expectedFormat.NotNil)
+			stream.Close()
+			return
+		}
+		c.OutputText(util.Status406(), "streaming is not available")
+	}, Timeout(time.Second)))
+
+	ts := httptest.NewServer(ServeHTTP())
+	defer ts.Close()
+
+	// [Test 1] Stream could not start while Timeout buffers response
+	response, _ := http.Get(ts.URL + "/events")
+	<-finished
+
+	if response.StatusCode != http.StatusNotAcceptable {
+		t.Errorf(expectedFormat.NumberButFoundNumber, http.StatusNotAcceptable, response.StatusCode)
+	}
+	if contentType := response.Header.Get("Content-Type"); strings.HasPrefix(contentType, "text/event-stream") {
+		t.Errorf(expectedFormat.StringButFoundString, "text/plain", contentType)
+	}
+	if cacheControl := response.Header.Get("Cache-Control"); len(cacheControl) > 0 {
+		t.Errorf(expectedFormat.StringButFoundString, "", cacheControl)
+	}
+}
@@ -0,0 +1,167 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/phuc0302/go-server/util"
+)
+
+// Context returns request's context, it will be cancelled when client disconnects or deadline passes.
+func (c *RequestContext) Context() context.Context {
+	return c.request.Context()
+}
+
+// SetContext replaces request's context, it should be derived from current one.
+//
+// Example:
+// c.SetContext(context.WithValue(c.Context(), userKey, user))
+func (c *RequestContext) SetContext(ctx context.Context) {
+	c.request = c.request.WithContext(ctx)
+}
+
+// Timeout generates adapter that enforces deadline on handler, client will receive 504 if handler
+// does not finish in time. Handler should watch c.Context() to stop its work early.
+//
+// Handler runs on a copy of request context, thus extras that it sets are visible to outer adapters
+// only if it finishes in time. Response is buffered & sent when handler returns, flushing fails with
+// http.ErrNotSupported, thus long-lived streams must not be wrapped.
+//
+// @param
+// - duration {time.Duration} (the handler's deadline)
+//
+// @return
+// - adapter {Adapter} (the timeout adapter)
+func Timeout(duration time.Duration) Adapter {
+	return func(f HandleContextFunc) HandleContextFunc {
+		return func(c *RequestContext) {
+			ctx, cancel := context.WithTimeout(c.Context(), duration)
+			defer cancel()
+			c.SetContext(ctx)
+
+			// Buffer response so that it can be discarded when deadline passes
+			writer := &timeoutWriter{header: make(http.Header)}
+			for name, values := range c.response.Header() {
+				writer.header[name] = values
+			}
+
+			// Handler may outlive deadline, it must not share mutable state with outer adapters
+			inner := *c
+			inner.response = writer
+			inner.extra = make(map[string]interface{}, len(c.extra))
+			for key, value := range c.extra {
+				inner.extra[key] = value
+			}
+
+			done := make(chan interface{}, 1)
+			go func() {
+				defer func() {
+					recovered := recover()
+					done <- recovered
+				}()
+				f(&inner)
+			}()
+
+			select {
+			case recovered := <-done:
+				response := c.response
+				*c = inner
+				c.response = response
+
+				if recovered != nil {
+					panic(recovered)
+				}
+				writer.flushTo(response)
+
+			case <-ctx.Done():
+				writer.mutex.Lock()
+				writer.timedOut = true
+				writer.mutex.Unlock()
+
+				// Client had gone away, there is no one to answer
+				if ctx.Err() == context.DeadlineExceeded {
+					writeProblem(c.response, c.request, util.Status504())
+				}
+			}
+		}
+	}
+}
+
+// timeoutWriter describes a response writer that buffers response until handler finishes.
+type timeoutWriter struct {
+	header   http.Header
+	status   int
+	body     bytes.Buffer
+	timedOut bool
+	mutex    sync.Mutex
+}
+
+// Header returns buffered response's header.
+func (w *timeoutWriter) Header() http.Header {
+	return w.header
+}
+
+// WriteHeader buffers status code.
+func (w *timeoutWriter) WriteHeader(status int) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.status == 0 && !w.timedOut {
+		w.status = status
+	}
+}
+
+// Write buffers data, it will fail once deadline passes.
+func (w *timeoutWriter) Write(data []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.body.Write(data)
+}
+
+// Flush does nothing since response is sent when handler returns.
+func (w *timeoutWriter) Flush() {
+	w.FlushError()
+}
+
+// FlushError always fails since response is buffered until handler returns, thus streaming handlers
+// such as SSE detect that they could not reach client.
+func (w *timeoutWriter) FlushError() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.timedOut {
+		return http.ErrHandlerTimeout
+	}
+	return http.ErrNotSupported
+}
+
+// flushTo sends buffered response to underline writer.
+func (w *timeoutWriter) flushTo(response http.ResponseWriter) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	header := response.Header()
+	for name := range header {
+		if _, ok := w.header[name]; !ok {
+			header.Del(name)
+		}
+	}
+	for name, values := range w.header {
+		header[name] = values
+	}
+
+	if w.status != 0 {
+		response.WriteHeader(w.status)
+	}
+	response.Write(w.body.Bytes())
+}
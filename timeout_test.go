@@ -0,0 +1,88 @@
+package server
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/phuc0302/go-server/expected_format"
+	"github.com/phuc0302/go-server/util"
+)
+
+type timeoutKey struct{}
+
+func Test_Timeout(t *testing.T) {
+	defer os.Remove(Debug)
+	Initialize(true)
+
+	// Setup test server
+	finished := make(chan struct{})
+	GroupRoute("/slow", func() {
+		BindGet("/fast", func(c *RequestContext) {
+			c.SetContext(context.WithValue(c.Context(), timeoutKey{}, "value"))
+			c.OutputText(util.Status200(), c.Context().Value(timeoutKey{}).(string))
+		})
+		BindGet("/sleep", func(c *RequestContext) {
+			select {
+			case <-c.Context().Done():
+			case <-time.After(time.Second):
+			}
+			c.SetExtra("late", true)
+			c.OutputText(util.Status200(), "late")
+			http.NewResponseController(c.response).Flush()
+			close(finished)
+		})
+		BindGet("/flush", func(c *RequestContext) {
+			c.OutputText(util.Status200(), "flushed")
+			if err := http.NewResponseController(c.response).Flush(); err != http.ErrNotSupported {
+				t.Errorf(expectedFormat.StringButFoundString, http.ErrNotSupported, err)
+			}
+		})
+		BindGet("/panic", func(c *RequestContext) {
+			panic(util.Status400())
+		})
+	}, func(f HandleContextFunc) HandleContextFunc {
+		// Outer adapter keeps using context while late handler is still running
+		return func(c *RequestContext) {
+			f(c)
+			c.SetExtra("outer", true)
+			if _, ok := c.GetExtra("late").(bool); ok {
+				t.Error("Expected late handler's extra had been discarded.")
+			}
+		}
+	}, Timeout(50*time.Millisecond))
+
+	ts := httptest.NewServer(ServeHTTP())
+	defer ts.Close()
+
+	// [Test 1] Handler finishes in time
+	response, _ := http.Get(ts.URL + "/slow/fast")
+	bytes, _ := ioutil.ReadAll(response.Body)
+	if response.StatusCode != 200 || string(bytes) != "value" {
+		t.Errorf(expectedFormat.StringButFoundString, "value", string(bytes))
+	}
+
+	// [Test 2] Deadline passes
+	response, _ = http.Get(ts.URL + "/slow/sleep")
+	if response.StatusCode != 504 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 504, response.StatusCode)
+	}
+	<-finished // late handler still reads Cfg, next test must not re-initialize it meanwhile
+
+	// [Test 3] Flush is not supported while response is buffered
+	response, _ = http.Get(ts.URL + "/slow/flush")
+	bytes, _ = ioutil.ReadAll(response.Body)
+	if string(bytes) != "flushed" {
+		t.Errorf(expectedFormat.StringButFoundString, "flushed", string(bytes))
+	}
+
+	// [Test 4] Panic is forwarded to recovery
+	response, _ = http.Get(ts.URL + "/slow/panic")
+	if response.StatusCode != 400 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 400, response.StatusCode)
+	}
+}
@@ -6,10 +6,24 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 )
 
+// validations caches compiled validation patterns, keyed by pattern.
+var validations sync.Map
+
 // BindForm binds data into given form object.
 func BindForm(values map[string]string, inputForm interface{}) error {
+	multiValues := make(map[string][]string, len(values))
+	for key, value := range values {
+		multiValues[key] = []string{value}
+	}
+	return BindFormValues(multiValues, inputForm)
+}
+
+// BindFormValues binds multi-value data into given form object. Slice fields such as []string or
+// []int receive every value of repeated key, other fields receive the first value.
+func BindFormValues(values map[string][]string, inputForm interface{}) error {
 	/* Condition validation */
 	if inputForm == nil {
 		panic(Status500WithDescription("InputForm must not be nil."))
@@ -32,106 +46,140 @@ func BindForm(values map[string]string, inputForm interface{}) error {
 		field := structField.Tag.Get("field")
 
 		if property.CanSet() && len(field) > 0 {
-			data := values[field]
-			propertyType := property.Type()
-
-			value := reflect.ValueOf(data)
-			dataType := reflect.TypeOf(data)
+			inputs := values[field]
+			validation := structField.Tag.Get("validation")
+
+			// Bind every value into slice
+			if propertyType := property.Type(); propertyType.Kind() == reflect.Slice {
+				slice := reflect.MakeSlice(propertyType, 0, len(inputs))
+				for _, input := range inputs {
+					value, err := convertValue(field, input, validation, propertyType.Elem())
+					if err != nil {
+						return err
+					}
+					if value.IsValid() {
+						slice = reflect.Append(slice, value)
+					}
+				}
 
-			/* Condition validation: Skip if value is not valid */
-			if !value.IsValid() {
+				if slice.Len() > 0 {
+					property.Set(slice)
+				}
 				continue
 			}
 
-			if dataType == propertyType && dataType.Kind() != reflect.String {
+			// Bind the first value
+			input := ""
+			if len(inputs) > 0 {
+				input = inputs[0]
+			}
+			value, err := convertValue(field, input, validation, property.Type())
+			if err != nil {
+				return err
+			}
+			if value.IsValid() {
 				property.Set(value)
+			}
+		}
+	}
+	return nil
+}
 
-			} else if dataType.Kind() == reflect.String {
-				input := strings.TrimSpace(value.String())
-				validation := structField.Tag.Get("validation")
+// convertValue validates & converts input to property's type, returned value will be invalid if
+// input could not be converted.
+func convertValue(field string, input string, validation string, propertyType reflect.Type) (reflect.Value, error) {
+	// Only values that need parsing are trimmed, string keeps its whitespace
+	trimmed := strings.TrimSpace(input)
+
+	// Validation input value before inject
+	if len(validation) > 0 {
+		regex, err := compileValidation(validation)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("Invalid '%s' validation: %s", field, err)
+		}
+		if !regex.MatchString(trimmed) {
+			return reflect.Value{}, fmt.Errorf("Invalid '%s' parameter.", field)
+		}
+	}
 
-				// Validation input value before inject
-				if len(validation) > 0 {
-					regex := regexp.MustCompile(validation)
-					if !regex.MatchString(input) {
-						return fmt.Errorf("Invalid '%s' parameter.", field)
-					}
-				}
+	// Convert process
+	var value interface{}
+	switch propertyType.Kind() {
 
-				// Convert process
-				switch propertyType.Kind() {
+	case reflect.Bool:
+		trimmed = strings.ToLower(trimmed)
 
-				case reflect.Bool:
-					input = strings.ToLower(input)
+		if b, err := strconv.ParseBool(trimmed); err == nil {
+			value = b
+		}
+	case reflect.Float32:
+		if f, err := strconv.ParseFloat(trimmed, 32); err == nil {
+			value = float32(f)
+		}
+	case reflect.Float64:
+		if f, err := strconv.ParseFloat(trimmed, 64); err == nil {
+			value = f
+		}
 
-					if b, err := strconv.ParseBool(input); err == nil {
-						property.Set(reflect.ValueOf(b))
-						break
-					}
-				case reflect.Float32:
-					if f, err := strconv.ParseFloat(input, 32); err == nil {
-						property.Set(reflect.ValueOf(float32(f)))
-						break
-					}
-				case reflect.Float64:
-					if f, err := strconv.ParseFloat(input, 64); err == nil {
-						property.Set(reflect.ValueOf(f))
-						break
-					}
+	case reflect.Int, reflect.Int32:
+		if integer, err := strconv.ParseInt(trimmed, 10, 32); err == nil {
+			value = int(integer)
+		}
+	case reflect.Int8:
+		if integer, err := strconv.ParseInt(trimmed, 10, 0); err == nil {
+			value = int8(integer)
+		}
+	case reflect.Int16:
+		if integer, err := strconv.ParseInt(trimmed, 10, 16); err == nil {
+			value = int16(integer)
+		}
+	case reflect.Int64:
+		if integer, err := strconv.ParseInt(trimmed, 10, 64); err == nil {
+			value = integer
+		}
 
-				case reflect.Int, reflect.Int32:
-					if integer, err := strconv.ParseInt(input, 10, 32); err == nil {
-						property.Set(reflect.ValueOf(int(integer)))
-						break
-					}
-				case reflect.Int8:
-					if integer, err := strconv.ParseInt(input, 10, 0); err == nil {
-						property.Set(reflect.ValueOf(int8(integer)))
-						break
-					}
-				case reflect.Int16:
-					if integer, err := strconv.ParseInt(input, 10, 16); err == nil {
-						property.Set(reflect.ValueOf(int16(integer)))
-						break
-					}
-				case reflect.Int64:
-					if integer, err := strconv.ParseInt(input, 10, 64); err == nil {
-						property.Set(reflect.ValueOf(integer))
-						break
-					}
+	case reflect.Uint, reflect.Uint32:
+		if unsignInteger, err := strconv.ParseUint(trimmed, 10, 32); err == nil {
+			value = uint(unsignInteger)
+		}
+	case reflect.Uint8:
+		if unsignInteger, err := strconv.ParseUint(trimmed, 10, 8); err == nil {
+			value = uint8(unsignInteger)
+		}
+	case reflect.Uint16:
+		if unsignInteger, err := strconv.ParseUint(trimmed, 10, 16); err == nil {
+			value = uint16(unsignInteger)
+		}
+	case reflect.Uint64:
+		if unsignInteger, err := strconv.ParseUint(trimmed, 10, 64); err == nil {
+			value = unsignInteger
+		}
 
-				case reflect.Uint, reflect.Uint32:
-					if unsignInteger, err := strconv.ParseUint(input, 10, 32); err == nil {
-						property.Set(reflect.ValueOf(uint(unsignInteger)))
-						break
-					}
-				case reflect.Uint8:
-					if unsignInteger, err := strconv.ParseUint(input, 10, 8); err == nil {
-						property.Set(reflect.ValueOf(uint8(unsignInteger)))
-						break
-					}
-				case reflect.Uint16:
-					if unsignInteger, err := strconv.ParseUint(input, 10, 16); err == nil {
-						property.Set(reflect.ValueOf(uint16(unsignInteger)))
-						break
-					}
-				case reflect.Uint64:
-					if unsignInteger, err := strconv.ParseUint(input, 10, 64); err == nil {
-						property.Set(reflect.ValueOf(unsignInteger))
-						break
-					}
+	case reflect.String:
+		if len(input) > 0 {
+			value = input
+		}
 
-				case reflect.String:
-					if len(value.String()) > 0 {
-						property.Set(value)
-						break
-					}
+	default:
+		return reflect.Value{}, fmt.Errorf("Invalid \"%s\" parameter.", field)
+	}
 
-				default:
-					return fmt.Errorf("Invalid \"%s\" parameter.", field)
-				}
-			}
-		}
+	if value == nil {
+		return reflect.Value{}, nil
 	}
-	return nil
+	return reflect.ValueOf(value).Convert(propertyType), nil
+}
+
+// compileValidation returns compiled validation pattern, each pattern is compiled only once.
+func compileValidation(validation string) (*regexp.Regexp, error) {
+	if regex, ok := validations.Load(validation); ok {
+		return regex.(*regexp.Regexp), nil
+	}
+
+	regex, err := regexp.Compile(validation)
+	if err != nil {
+		return nil, err
+	}
+	validations.Store(validation, regex)
+	return regex, nil
 }
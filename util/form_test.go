@@ -1,6 +1,7 @@
 package util
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/phuc0302/go-server/expected_format"
@@ -112,3 +113,72 @@ func Test_BindForm_InternalForm(t *testing.T) {
 		t.Errorf("Expected %d but found %d.", 200, form.ValueUInt)
 	}
 }
+
+func Test_BindFormValues(t *testing.T) {
+	values := map[string][]string{
+		"tags": {"go", "http"},
+		"ids":  {"1", "2", "3"},
+		"name": {"first", "second"},
+	}
+
+	var form struct {
+		Tags []string `field:"tags" validation:"^\\w+$"`
+		IDs  []int    `field:"ids"`
+		Name string   `field:"name"`
+	}
+	err := BindFormValues(values, &form)
+
+	// [Test 1] Every value is bound into slice
+	if err != nil {
+		t.Error(expectedFormat.Nil)
+	}
+	if len(form.Tags) != 2 || form.Tags[1] != "http" {
+		t.Errorf(expectedFormat.StringButFoundString, "http", strings.Join(form.Tags, ","))
+	}
+	if len(form.IDs) != 3 || form.IDs[2] != 3 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 3, len(form.IDs))
+	}
+	if form.Name != "first" {
+		t.Errorf(expectedFormat.StringButFoundString, "first", form.Name)
+	}
+
+	// [Test 2] Every value must pass validation
+	values["tags"] = append(values["tags"], "not valid")
+	if err := BindFormValues(values, &form); err == nil {
+		t.Error(expectedFormat.NotNil)
+	}
+
+	// [Test 3] Invalid validation pattern returns error
+	var invalid struct {
+		Name string `field:"name" validation:"("`
+	}
+	if err := BindFormValues(values, &invalid); err == nil {
+		t.Error(expectedFormat.NotNil)
+	}
+}
+
+func Test_BindForm_Whitespace(t *testing.T) {
+	values := map[string]string{
+		"string": "  A String ",
+		"bool":   " true ",
+		"float":  " 1.5 ",
+		"int":    " -100 ",
+	}
+	testStruct := new(TestStruct)
+
+	// [Test 1] String keeps its whitespace while it is validated without it
+	if err := BindForm(values, testStruct); err != nil {
+		t.Error(expectedFormat.Nil)
+	}
+	if testStruct.ValueString != "  A String " {
+		t.Errorf(expectedFormat.StringButFoundString, "  A String ", testStruct.ValueString)
+	}
+
+	// [Test 2] Values that need parsing are trimmed
+	if !testStruct.ValueBool {
+		t.Errorf(expectedFormat.BoolButFoundBool, true, testStruct.ValueBool)
+	}
+	if testStruct.ValueInt != -100 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, -100, testStruct.ValueInt)
+	}
+}
@@ -2,16 +2,19 @@ package util
 
 import (
 	"encoding/json"
+	"encoding/xml"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 )
 
 /// Status describes a HTTP status component.
 type Status struct {
-	Code        int         `json:"status,omitempty"`
-	Error       string      `json:"error,omitempty"`
-	Description string      `json:"error_description,omitempty"`
-	StackTrace  interface{} `json:"stack_trace,omitempty"`
+	XMLName     xml.Name    `json:"-" xml:"urn:ietf:rfc:7807 problem"`
+	Code        int         `json:"status,omitempty" xml:"status,omitempty"`
+	Error       string      `json:"error,omitempty" xml:"title,omitempty"`
+	Description string      `json:"error_description,omitempty" xml:"detail,omitempty"`
+	StackTrace  interface{} `json:"stack_trace,omitempty" xml:"-"`
 }
 
 func Status200() *Status {
@@ -348,3 +351,26 @@ func specificStatus(statusCode int, title string) *Status {
 		Description: title,
 	}
 }
+
+// StatusError wraps status so that it can be returned as error.
+type StatusError struct {
+	Status *Status
+}
+
+// Error returns status's code & description.
+func (e *StatusError) Error() string {
+	return e.Status.String()
+}
+
+// AsError wraps status as error, it should be used by HandleErrorFunc.
+//
+// Example:
+// return util.Status404().AsError()
+func (s *Status) AsError() error {
+	return &StatusError{Status: s}
+}
+
+// String returns status's code & description.
+func (s *Status) String() string {
+	return fmt.Sprintf("%d %s", s.Code, s.Description)
+}
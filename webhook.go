@@ -0,0 +1,103 @@
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"hash"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/phuc0302/go-server/util"
+)
+
+// WebhookOptions describes how inbound webhook's signature is verified.
+type WebhookOptions struct {
+	Secret          []byte           // The shared secret
+	Hash            func() hash.Hash // Default is sha256.New, sha512.New is also common
+	SignatureHeader string           // Default is "X-Signature"
+	SignaturePrefix string           // Prefix before signature, e.g. "sha256="
+	TimestampHeader string           // Unix timestamp header, empty disables replay protection
+	Tolerance       time.Duration    // Default is 5 minutes
+}
+
+// VerifyWebhook generates adapter that verifies HMAC signature over request's raw body. Signature can
+// be either hex or base64 encoded. When timestamp header is defined, the signed payload will be
+// "timestamp.body" & request that is older than tolerance will be rejected.
+//
+// Body stays available to BindJSON afterward, multipart forms are consumed before adapter is called
+// thus they cannot be verified.
+//
+// @param
+// - options {WebhookOptions} (the signature's options)
+//
+// @return
+// - adapter {Adapter} (the webhook adapter)
+func VerifyWebhook(options WebhookOptions) Adapter {
+	/* Condition validation: validate secret */
+	if len(options.Secret) == 0 {
+		panic("Webhook's secret must not be empty.")
+	}
+	if options.Hash == nil {
+		options.Hash = sha256.New
+	}
+	if len(options.SignatureHeader) == 0 {
+		options.SignatureHeader = "X-Signature"
+	}
+	if options.Tolerance <= 0 {
+		options.Tolerance = 5 * time.Minute
+	}
+
+	return func(f HandleContextFunc) HandleContextFunc {
+		return func(c *RequestContext) {
+			body, err := c.readBody()
+			if err != nil {
+				c.outputProblem(bodyErrorStatus(err))
+				return
+			}
+
+			mac := hmac.New(options.Hash, options.Secret)
+			if len(options.TimestampHeader) > 0 {
+				timestamp := c.request.Header.Get(options.TimestampHeader)
+				if !isTimestampValid(timestamp, options.Tolerance) {
+					c.outputProblem(util.Status401WithDescription("Webhook's timestamp is out of tolerance."))
+					return
+				}
+				mac.Write([]byte(timestamp + "."))
+			}
+			mac.Write(body)
+
+			/* Condition validation: validate signature */
+			signature := c.request.Header.Get(options.SignatureHeader)
+			if !strings.HasPrefix(signature, options.SignaturePrefix) || !isSignatureValid(strings.TrimPrefix(signature, options.SignaturePrefix), mac.Sum(nil)) {
+				c.outputProblem(util.Status401WithDescription("Invalid webhook's signature."))
+				return
+			}
+			f(c)
+		}
+	}
+}
+
+// isTimestampValid checks if unix timestamp is within tolerance or not.
+func isTimestampValid(timestamp string, tolerance time.Duration) bool {
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+
+	delta := time.Since(time.Unix(seconds, 0))
+	return delta <= tolerance && delta >= -tolerance
+}
+
+// isSignatureValid compares hex or base64 encoded signature against expected MAC in constant time.
+func isSignatureValid(signature string, expected []byte) bool {
+	if decoded, err := hex.DecodeString(signature); err == nil && hmac.Equal(decoded, expected) {
+		return true
+	}
+	if decoded, err := base64.StdEncoding.DecodeString(signature); err == nil && hmac.Equal(decoded, expected) {
+		return true
+	}
+	return false
+}
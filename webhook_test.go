@@ -0,0 +1,71 @@
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/phuc0302/go-server/expected_format"
+	"github.com/phuc0302/go-server/util"
+)
+
+func Test_VerifyWebhook(t *testing.T) {
+	secret := []byte("webhook-secret")
+	handler := Adapt(func(c *RequestContext) {
+		var event map[string]string
+		c.BindJSON(&event)
+		c.OutputText(util.Status200(), event["type"])
+	}, VerifyWebhook(WebhookOptions{
+		Secret:          secret,
+		Hash:            sha512.New,
+		SignatureHeader: "X-Hub-Signature",
+		SignaturePrefix: "sha512=",
+		TimestampHeader: "X-Hub-Timestamp",
+	}))
+
+	// Create test server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler(CreateContext(w, r))
+	}))
+	defer ts.Close()
+
+	post := func(body string, timestamp time.Time, tamper bool) (int, string) {
+		unix := fmt.Sprintf("%d", timestamp.Unix())
+		mac := hmac.New(sha512.New, secret)
+		mac.Write([]byte(unix + "." + body))
+		if tamper {
+			body += " "
+		}
+
+		request, _ := http.NewRequest("POST", ts.URL, strings.NewReader(body))
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set("X-Hub-Signature", "sha512="+hex.EncodeToString(mac.Sum(nil)))
+		request.Header.Set("X-Hub-Timestamp", unix)
+
+		response, _ := http.DefaultClient.Do(request)
+		bytes, _ := ioutil.ReadAll(response.Body)
+		return response.StatusCode, string(bytes)
+	}
+
+	// [Test 1] Valid signature, body is still available
+	if code, body := post("{\"type\":\"payment\"}", time.Now(), false); code != 200 || body != "payment" {
+		t.Errorf(expectedFormat.StringButFoundString, "payment", body)
+	}
+
+	// [Test 2] Tampered body
+	if code, _ := post("{\"type\":\"payment\"}", time.Now(), true); code != 401 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 401, code)
+	}
+
+	// [Test 3] Replayed request
+	if code, _ := post("{\"type\":\"payment\"}", time.Now().Add(-time.Hour), false); code != 401 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 401, code)
+	}
+}
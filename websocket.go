@@ -0,0 +1,684 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/phuc0302/go-server/util"
+)
+
+// WebSocket message types, they are also frame's opcodes.
+const (
+	TextMessage   = 1
+	BinaryMessage = 2
+	CloseMessage  = 8
+	PingMessage   = 9
+	PongMessage   = 10
+)
+
+// WebSocket close codes.
+const (
+	CloseNormalClosure    = 1000
+	CloseGoingAway        = 1001
+	CloseProtocolError    = 1002
+	CloseUnsupportedData  = 1003
+	CloseNoStatusReceived = 1005
+	CloseAbnormalClosure  = 1006
+	CloseInvalidPayload   = 1007
+	ClosePolicyViolation  = 1008
+	CloseMessageTooBig    = 1009
+	CloseInternalError    = 1011
+)
+
+// websocketGUID is the GUID that RFC 6455 uses to compute Sec-WebSocket-Accept.
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// continuationFrame is the opcode of message's following fragments.
+const continuationFrame = 0
+
+// deflateTail is the tail that permessage-deflate strips from every compressed message.
+var deflateTail = []byte{0x00, 0x00, 0xff, 0xff}
+
+// deflaters keeps flate writers for reuse, they are expensive to allocate.
+var deflaters = sync.Pool{
+	New: func() interface{} {
+		writer, _ := flate.NewWriter(nil, flate.DefaultCompression)
+		return writer
+	},
+}
+
+// errWebSocketClosed is returned when writing to a closed connection.
+var errWebSocketClosed = errors.New("websocket: connection had been closed")
+
+// HandleWebSocketFunc defines type alias for WebSocket func callback handler, connection will be
+// closed when handler returns.
+//
+// @param
+// - context {RequestContext} (the upgrade request's context)
+// - ws {WebSocket} (the upgraded connection)
+type HandleWebSocketFunc func(*RequestContext, *WebSocket)
+
+// WebSocketOptions describes how connection is upgraded & kept alive.
+type WebSocketOptions struct {
+	Subprotocols   []string                   // Supported subprotocols in preference order
+	MaxMessageSize int64                      // In bytes, default is 1 MB
+	PingInterval   time.Duration              // Default is 30 seconds, negative disables keepalive
+	PongTimeout    time.Duration              // Default is 10 seconds
+	Compression    bool                       // Negotiate permessage-deflate with client
+	CheckOrigin    func(*RequestContext) bool // Default accepts missing Origin or the same host
+}
+
+// CloseError describes a close frame that ended the connection.
+type CloseError struct {
+	Code   int
+	Reason string
+}
+
+// Error returns close error's description.
+func (e *CloseError) Error() string {
+	return fmt.Sprintf("websocket: close %d %s", e.Code, e.Reason)
+}
+
+// WebSocket describes an upgraded WebSocket connection. ReadMessage must be called from one goroutine
+// at a time, writing is safe from any goroutine.
+type WebSocket struct {
+	Subprotocol string // The negotiated subprotocol, empty if none
+
+	conn         net.Conn
+	reader       *bufio.Reader
+	options      WebSocketOptions
+	compress     bool
+	writeTimeout time.Duration
+	closed       chan struct{}
+	writeMutex   sync.Mutex
+}
+
+// frame describes a single WebSocket frame.
+type frame struct {
+	fin     bool
+	rsv1    bool
+	opcode  int
+	payload []byte
+}
+
+// BindWebSocket routes WebSocket upgrade request to registered handler with default options.
+//
+// @param
+// - patternURL {string} (the URL matching pattern)
+// - handler {HandleWebSocketFunc} (the callback func)
+func BindWebSocket(patternURL string, handler HandleWebSocketFunc) {
+	router.BindRoute(Get, patternURL, UpgradeWebSocket(WebSocketOptions{}, handler))
+}
+
+// UpgradeWebSocket generates handler that upgrades request to WebSocket connection, it can be bound by
+// BindGet & decorated by adapters, e.g. authentication, which run before the upgrade. Panic within
+// handler closes connection with 1011 instead of reaching Recovery, since connection no longer speaks
+// HTTP. Upgraded connections count towards MaxInflight until they are closed.
+//
+// @param
+// - options {WebSocketOptions} (the connection's options)
+// - handler {HandleWebSocketFunc} (the callback func)
+//
+// @return
+// - handler {HandleContextFunc} (the upgrade handler)
+func UpgradeWebSocket(options WebSocketOptions, handler HandleWebSocketFunc) HandleContextFunc {
+	/* Condition validation: validate handler */
+	if handler == nil {
+		panic("WebSocket handler must not be nil.")
+	}
+	if options.MaxMessageSize <= 0 {
+		options.MaxMessageSize = 1 << 20
+	}
+	if options.PingInterval == 0 {
+		options.PingInterval = 30 * time.Second
+	}
+	if options.PongTimeout <= 0 {
+		options.PongTimeout = 10 * time.Second
+	}
+	if options.CheckOrigin == nil {
+		options.CheckOrigin = isSameOrigin
+	}
+
+	return func(c *RequestContext) {
+		ws, err := upgradeWebSocket(c, options)
+		if err != nil {
+			var statusError *util.StatusError
+			if errors.As(err, &statusError) {
+				c.outputProblem(statusError.Status)
+			} else {
+				// Connection had been hijacked, there is no HTTP response to write
+				logrus.Warningf("[WebSocket] Could not send handshake: %s", err)
+			}
+			return
+		}
+
+		defer func() {
+			if err := recover(); err != nil {
+				logrus.Warningf("[WebSocket] %s %s: %v\n%s", c.request.Method, c.Path, err, debug.Stack())
+				ws.Close(CloseInternalError, "")
+				return
+			}
+			ws.Close(CloseNormalClosure, "")
+		}()
+
+		ws.keepalive()
+		handler(c, ws)
+	}
+}
+
+// upgradeWebSocket validates handshake, hijacks connection & sends handshake's response. Returned
+// error will be StatusError if connection is not yet hijacked.
+func upgradeWebSocket(c *RequestContext, options WebSocketOptions) (*WebSocket, error) {
+	header := c.response.Header()
+
+	/* Condition validation: validate handshake */
+	if !containsToken(c.HeaderList("Connection"), "upgrade") || !containsToken(c.HeaderList("Upgrade"), "websocket") {
+		header.Set("Connection", "Upgrade")
+		header.Set("Upgrade", "websocket")
+		return nil, util.Status426WithDescription("WebSocket upgrade is required.").AsError()
+	}
+	if c.HeaderValue("Sec-WebSocket-Version") != "13" {
+		header.Set("Sec-WebSocket-Version", "13")
+		return nil, util.Status426WithDescription("Unsupported WebSocket version.").AsError()
+	}
+	key := strings.TrimSpace(c.HeaderValue("Sec-WebSocket-Key"))
+	if decoded, err := base64.StdEncoding.DecodeString(key); err != nil || len(decoded) != 16 {
+		return nil, util.Status400WithDescription("Invalid Sec-WebSocket-Key.").AsError()
+	}
+	if !options.CheckOrigin(c) {
+		return nil, util.Status403WithDescription("Origin is not allowed.").AsError()
+	}
+
+	ws := &WebSocket{
+		options: options,
+		closed:  make(chan struct{}),
+	}
+	if Cfg != nil {
+		ws.writeTimeout = Cfg.WriteTimeout
+	}
+	ws.Subprotocol = selectSubprotocol(c.HeaderList("Sec-WebSocket-Protocol"), options.Subprotocols)
+	ws.compress = options.Compression && acceptDeflate(c.HeaderList("Sec-WebSocket-Extensions"))
+
+	// Prepare handshake's response, headers from adapters are kept
+	header.Set("Upgrade", "websocket")
+	header.Set("Connection", "Upgrade")
+	header.Set("Sec-WebSocket-Accept", computeAcceptKey(key))
+	if len(ws.Subprotocol) > 0 {
+		header.Set("Sec-WebSocket-Protocol", ws.Subprotocol)
+	}
+	if ws.compress {
+		header.Set("Sec-WebSocket-Extensions", "permessage-deflate; server_no_context_takeover; client_no_context_takeover")
+	}
+
+	conn, buffer, err := http.NewResponseController(c.response).Hijack()
+	if err != nil {
+		logrus.Warningf("[WebSocket] Could not hijack connection: %s", err)
+		return nil, util.Status500WithDescription("Connection could not be upgraded.").AsError()
+	}
+	ws.conn = conn
+	ws.reader = buffer.Reader
+
+	// Server's deadlines are meant for HTTP, WebSocket manages its own
+	conn.SetDeadline(time.Time{})
+
+	var response bytes.Buffer
+	response.WriteString("HTTP/1.1 101 Switching Protocols\r\n")
+	header.WriteSubset(&response, map[string]bool{"Content-Length": true, "Content-Type": true, "Transfer-Encoding": true})
+	response.WriteString("\r\n")
+
+	ws.extendWriteDeadline()
+	if _, err := conn.Write(response.Bytes()); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return ws, nil
+}
+
+// ReadMessage reads the next data message, fragments are joined & control frames are handled along
+// the way. Returned error will be CloseError when client closes connection or violates protocol.
+//
+// @return
+// - messageType {int} (either TextMessage or BinaryMessage)
+// - data {[]byte} (the message's data)
+// - err {error} (error during read process)
+func (ws *WebSocket) ReadMessage() (messageType int, data []byte, err error) {
+	var compressed bool
+	for {
+		f, err := ws.readFrame(ws.options.MaxMessageSize - int64(len(data)))
+		if err != nil {
+			return 0, nil, ws.fail(err)
+		}
+
+		switch f.opcode {
+		case PingMessage:
+			if err := ws.writeFrame(PongMessage, f.payload); err != nil {
+				return 0, nil, err
+			}
+			continue
+
+		case PongMessage:
+			continue
+
+		case CloseMessage:
+			return 0, nil, ws.handleClose(f.payload)
+
+		case continuationFrame:
+			if messageType == 0 {
+				return 0, nil, ws.fail(&CloseError{CloseProtocolError, "Unexpected continuation frame."})
+			}
+			if f.rsv1 {
+				return 0, nil, ws.fail(&CloseError{CloseProtocolError, "Continuation frame must not be compressed."})
+			}
+
+		default:
+			if messageType != 0 {
+				return 0, nil, ws.fail(&CloseError{CloseProtocolError, "Expected continuation frame."})
+			}
+			messageType = f.opcode
+			compressed = f.rsv1
+		}
+
+		data = append(data, f.payload...)
+		if f.fin {
+			break
+		}
+	}
+
+	if compressed {
+		if data, err = inflate(data, ws.options.MaxMessageSize); err != nil {
+			return 0, nil, ws.fail(err)
+		}
+	}
+	if messageType == TextMessage && !utf8.Valid(data) {
+		return 0, nil, ws.fail(&CloseError{CloseInvalidPayload, "Text message must be UTF-8."})
+	}
+	return messageType, data, nil
+}
+
+// ReadJSON reads the next message & converts it to object.
+//
+// @param
+// - model {interface} (an associated object model)
+//
+// @return
+// - err {error} (error during read process)
+func (ws *WebSocket) ReadJSON(model interface{}) error {
+	_, data, err := ws.ReadMessage()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, model)
+}
+
+// WriteMessage sends data as a single message, it will be compressed if client had negotiated it.
+//
+// @param
+// - messageType {int} (either TextMessage or BinaryMessage)
+// - data {[]byte} (the message's data)
+//
+// @return
+// - err {error} (error during write process)
+func (ws *WebSocket) WriteMessage(messageType int, data []byte) error {
+	/* Condition validation: validate message type */
+	if messageType != TextMessage && messageType != BinaryMessage {
+		return fmt.Errorf("websocket: invalid message type %d", messageType)
+	}
+	return ws.writeFrame(messageType, data)
+}
+
+// WriteJSON sends object as JSON text message.
+//
+// @param
+// - model {interface} (an associated object model)
+//
+// @return
+// - err {error} (error during write process)
+func (ws *WebSocket) WriteJSON(model interface{}) error {
+	data, err := json.Marshal(model)
+	if err != nil {
+		return err
+	}
+	return ws.writeFrame(TextMessage, data)
+}
+
+// Ping sends a ping frame, client's pong is consumed by ReadMessage.
+func (ws *WebSocket) Ping(data []byte) error {
+	return ws.writeFrame(PingMessage, data)
+}
+
+// Done returns a channel that is closed when connection is closed.
+func (ws *WebSocket) Done() <-chan struct{} {
+	return ws.closed
+}
+
+// Close sends close frame & closes connection, calling it more than once has no effect.
+//
+// @param
+// - code {int} (the close code, CloseNoStatusReceived sends close frame without code)
+// - reason {string} (the close reason, will be truncated to fit into control frame)
+//
+// @return
+// - err {error} (error during write process)
+func (ws *WebSocket) Close(code int, reason string) error {
+	ws.writeMutex.Lock()
+	defer ws.writeMutex.Unlock()
+
+	select {
+	case <-ws.closed:
+		return errWebSocketClosed
+	default:
+	}
+
+	var payload []byte
+	if code != CloseNoStatusReceived {
+		if len(reason) > 123 {
+			n := 123
+			for n > 0 && !utf8.RuneStart(reason[n]) {
+				n--
+			}
+			reason = reason[:n]
+		}
+		payload = binary.BigEndian.AppendUint16(payload, uint16(code))
+		payload = append(payload, reason...)
+	}
+	err := ws.write(CloseMessage, payload)
+
+	close(ws.closed)
+	ws.conn.Close()
+	return err
+}
+
+// keepalive pings client every interval until connection is closed, client that stays silent beyond
+// ping interval & pong timeout will be disconnected by read deadline.
+func (ws *WebSocket) keepalive() {
+	if ws.options.PingInterval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(ws.options.PingInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if err := ws.writeFrame(PingMessage, nil); err != nil {
+					return
+				}
+			case <-ws.closed:
+				return
+			}
+		}
+	}()
+}
+
+// readFrame reads the next frame, limit is the remaining size of current message.
+func (ws *WebSocket) readFrame(limit int64) (*frame, error) {
+	if ws.options.PingInterval > 0 {
+		ws.conn.SetReadDeadline(time.Now().Add(ws.options.PingInterval + ws.options.PongTimeout))
+	}
+
+	var head [2]byte
+	if _, err := io.ReadFull(ws.reader, head[:]); err != nil {
+		return nil, err
+	}
+	f := &frame{
+		fin:    head[0]&0x80 != 0,
+		rsv1:   head[0]&0x40 != 0,
+		opcode: int(head[0] & 0x0f),
+	}
+	isControl := f.opcode >= CloseMessage
+	length := uint64(head[1] & 0x7f)
+
+	/* Condition validation: validate frame's header */
+	if head[0]&0x30 != 0 || (f.rsv1 && (!ws.compress || isControl)) {
+		return nil, &CloseError{CloseProtocolError, "Unexpected reserved bits."}
+	}
+	if head[1]&0x80 == 0 {
+		return nil, &CloseError{CloseProtocolError, "Client's frame must be masked."}
+	}
+	switch f.opcode {
+	case continuationFrame, TextMessage, BinaryMessage, CloseMessage, PingMessage, PongMessage:
+	default:
+		return nil, &CloseError{CloseProtocolError, fmt.Sprintf("Unknown opcode %d.", f.opcode)}
+	}
+	if isControl && (!f.fin || length > 125) {
+		return nil, &CloseError{CloseProtocolError, "Invalid control frame."}
+	}
+
+	// Extended payload length
+	switch length {
+	case 126:
+		var extended [2]byte
+		if _, err := io.ReadFull(ws.reader, extended[:]); err != nil {
+			return nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(extended[:]))
+	case 127:
+		var extended [8]byte
+		if _, err := io.ReadFull(ws.reader, extended[:]); err != nil {
+			return nil, err
+		}
+		length = binary.BigEndian.Uint64(extended[:])
+	}
+	if !isControl && length > uint64(limit) {
+		return nil, &CloseError{CloseMessageTooBig, "Message is too big."}
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(ws.reader, mask[:]); err != nil {
+		return nil, err
+	}
+	f.payload = make([]byte, length)
+	if _, err := io.ReadFull(ws.reader, f.payload); err != nil {
+		return nil, err
+	}
+	for idx := range f.payload {
+		f.payload[idx] ^= mask[idx%4]
+	}
+	return f, nil
+}
+
+// handleClose replies client's close frame & returns it as CloseError.
+func (ws *WebSocket) handleClose(payload []byte) error {
+	closeError := &CloseError{Code: CloseNoStatusReceived}
+	if len(payload) > 0 {
+		/* Condition validation: validate close frame's payload */
+		if len(payload) < 2 {
+			return ws.fail(&CloseError{CloseProtocolError, "Invalid close frame."})
+		}
+		closeError.Code = int(binary.BigEndian.Uint16(payload))
+		closeError.Reason = string(payload[2:])
+
+		if !isValidCloseCode(closeError.Code) || !utf8.ValidString(closeError.Reason) {
+			return ws.fail(&CloseError{CloseProtocolError, "Invalid close frame."})
+		}
+	}
+
+	ws.Close(closeError.Code, "")
+	return closeError
+}
+
+// fail closes connection because of read error, protocol violations are reported to client.
+func (ws *WebSocket) fail(err error) error {
+	if closeError, ok := err.(*CloseError); ok {
+		ws.Close(closeError.Code, closeError.Reason)
+	} else {
+		ws.Close(CloseNoStatusReceived, "")
+	}
+	return err
+}
+
+// writeFrame sends a single final frame, data messages are compressed if it had been negotiated.
+func (ws *WebSocket) writeFrame(opcode int, payload []byte) error {
+	ws.writeMutex.Lock()
+	defer ws.writeMutex.Unlock()
+
+	select {
+	case <-ws.closed:
+		return errWebSocketClosed
+	default:
+	}
+	return ws.write(opcode, payload)
+}
+
+// write sends frame, write mutex must be held.
+func (ws *WebSocket) write(opcode int, payload []byte) error {
+	head := []byte{0x80 | byte(opcode), 0}
+	if ws.compress && opcode < CloseMessage {
+		head[0] |= 0x40
+		payload = deflate(payload)
+	}
+
+	switch length := len(payload); {
+	case length <= 125:
+		head[1] = byte(length)
+	case length <= 0xffff:
+		head[1] = 126
+		head = binary.BigEndian.AppendUint16(head, uint16(length))
+	default:
+		head[1] = 127
+		head = binary.BigEndian.AppendUint64(head, uint64(length))
+	}
+
+	ws.extendWriteDeadline()
+	buffers := net.Buffers{head, payload}
+	_, err := buffers.WriteTo(ws.conn)
+	return err
+}
+
+// extendWriteDeadline applies server's WriteTimeout to each write instead of the whole connection.
+func (ws *WebSocket) extendWriteDeadline() {
+	var deadline time.Time
+	if ws.writeTimeout > 0 {
+		deadline = time.Now().Add(ws.writeTimeout)
+	}
+	ws.conn.SetWriteDeadline(deadline)
+}
+
+// deflate compresses message without context takeover.
+func deflate(data []byte) []byte {
+	var buffer bytes.Buffer
+	writer := deflaters.Get().(*flate.Writer)
+	defer deflaters.Put(writer)
+
+	writer.Reset(&buffer)
+	writer.Write(data)
+	writer.Flush()
+	return bytes.TrimSuffix(buffer.Bytes(), deflateTail)
+}
+
+// inflate decompresses message, decompressed size is limited to prevent compression bomb.
+func inflate(data []byte, limit int64) ([]byte, error) {
+	// Final empty block lets reader ends without unexpected EOF
+	source := io.MultiReader(bytes.NewReader(data), bytes.NewReader(deflateTail), bytes.NewReader([]byte{0x01, 0x00, 0x00, 0xff, 0xff}))
+	reader := flate.NewReader(source)
+	defer reader.Close()
+
+	result, err := io.ReadAll(io.LimitReader(reader, limit+1))
+	if err != nil {
+		return nil, &CloseError{CloseInvalidPayload, "Message could not be decompressed."}
+	}
+	if int64(len(result)) > limit {
+		return nil, &CloseError{CloseMessageTooBig, "Message is too big."}
+	}
+	return result, nil
+}
+
+// acceptDeflate returns true if one of client's offers is permessage-deflate that server can follow.
+func acceptDeflate(offers []string) bool {
+	for _, offer := range offers {
+		params := strings.Split(offer, ";")
+		if !strings.EqualFold(strings.TrimSpace(params[0]), "permessage-deflate") {
+			continue
+		}
+
+		acceptable := true
+		for _, param := range params[1:] {
+			name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			value = strings.Trim(strings.TrimSpace(value), `"`)
+
+			switch strings.ToLower(strings.TrimSpace(name)) {
+			case "server_no_context_takeover", "client_no_context_takeover", "client_max_window_bits":
+			case "server_max_window_bits":
+				// Compressor always uses 32 KB window
+				acceptable = acceptable && value == "15"
+			default:
+				acceptable = false
+			}
+		}
+		if acceptable {
+			return true
+		}
+	}
+	return false
+}
+
+// selectSubprotocol returns the first server's subprotocol that client supports.
+func selectSubprotocol(requested []string, supported []string) string {
+	for _, subprotocol := range supported {
+		for _, item := range requested {
+			if item == subprotocol {
+				return subprotocol
+			}
+		}
+	}
+	return ""
+}
+
+// computeAcceptKey computes Sec-WebSocket-Accept from client's key.
+func computeAcceptKey(key string) string {
+	hash := sha1.Sum([]byte(key + websocketGUID))
+	return base64.StdEncoding.EncodeToString(hash[:])
+}
+
+// containsToken returns true if list contains token, case-insensitive.
+func containsToken(list []string, token string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, token) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSameOrigin accepts request without Origin, e.g. non-browser clients, or from the same host.
+func isSameOrigin(c *RequestContext) bool {
+	origin := c.HeaderValue("Origin")
+	if len(origin) == 0 {
+		return true
+	}
+
+	originURL, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(originURL.Host, c.Host())
+}
+
+// isValidCloseCode returns true if code can be sent in close frame.
+func isValidCloseCode(code int) bool {
+	switch {
+	case code >= 1000 && code <= 1003, code >= 1007 && code <= 1011:
+		return true
+	default:
+		return code >= 3000 && code <= 4999
+	}
+}
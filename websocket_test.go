@@ -0,0 +1,330 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/phuc0302/go-server/expected_format"
+)
+
+func Test_WebSocket_Echo(t *testing.T) {
+	defer os.Remove(Debug)
+	Initialize(true)
+
+	// Setup test server
+	done := make(chan struct{})
+	BindGet("/chat/{room}", UpgradeWebSocket(WebSocketOptions{Subprotocols: []string{"chat.v2", "chat.v1"}, Compression: true}, func(c *RequestContext, ws *WebSocket) {
+		defer close(done)
+		for {
+			messageType, data, err := ws.ReadMessage()
+			if err != nil {
+				return
+			}
+			ws.WriteMessage(messageType, append([]byte(c.PathParams["room"]+": "), data...))
+		}
+	}))
+	ts := httptest.NewServer(ServeHTTP())
+	defer ts.Close()
+
+	header := http.Header{}
+	header.Set("Sec-WebSocket-Protocol", "chat.v1, chat.v2")
+	header.Set("Sec-WebSocket-Extensions", "permessage-deflate; client_max_window_bits")
+	conn, reader, response := dialWebSocket(t, ts.URL, "/chat/lobby", header)
+	defer conn.Close()
+
+	if response.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf(expectedFormat.NumberButFoundNumber, http.StatusSwitchingProtocols, response.StatusCode)
+	}
+	if accept := response.Header.Get("Sec-WebSocket-Accept"); accept != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf(expectedFormat.StringButFoundString, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", accept)
+	}
+	if protocol := response.Header.Get("Sec-WebSocket-Protocol"); protocol != "chat.v2" {
+		t.Errorf(expectedFormat.StringButFoundString, "chat.v2", protocol)
+	}
+	if !strings.HasPrefix(response.Header.Get("Sec-WebSocket-Extensions"), "permessage-deflate") {
+		t.Errorf(expectedFormat.StringButFoundString, "permessage-deflate", response.Header.Get("Sec-WebSocket-Extensions"))
+	}
+
+	// Fragmented message
+	writeClientFrame(conn, TextMessage, false, false, []byte("hello "))
+	writeClientFrame(conn, continuationFrame, true, false, []byte("world"))
+
+	opcode, compressed, payload := readServerFrame(t, reader)
+	if opcode != TextMessage || !compressed {
+		t.Errorf(expectedFormat.NumberButFoundNumber, TextMessage, opcode)
+	}
+	if message := string(inflateTest(t, payload)); message != "lobby: hello world" {
+		t.Errorf(expectedFormat.StringButFoundString, "lobby: hello world", message)
+	}
+
+	// Compressed message
+	writeClientFrame(conn, BinaryMessage, true, true, deflate([]byte("compressed")))
+	opcode, _, payload = readServerFrame(t, reader)
+	if message := string(inflateTest(t, payload)); opcode != BinaryMessage || message != "lobby: compressed" {
+		t.Errorf(expectedFormat.StringButFoundString, "lobby: compressed", message)
+	}
+
+	// Ping
+	writeClientFrame(conn, PingMessage, true, false, []byte("ping"))
+	if opcode, _, payload = readServerFrame(t, reader); opcode != PongMessage || string(payload) != "ping" {
+		t.Errorf(expectedFormat.StringButFoundString, "ping", string(payload))
+	}
+
+	// Close handshake
+	writeClientFrame(conn, CloseMessage, true, false, binary.BigEndian.AppendUint16(nil, CloseGoingAway))
+	if opcode, _, payload = readServerFrame(t, reader); opcode != CloseMessage || binary.BigEndian.Uint16(payload) != CloseGoingAway {
+		t.Errorf(expectedFormat.NumberButFoundNumber, CloseGoingAway, binary.BigEndian.Uint16(payload))
+	}
+	<-done
+}
+
+func Test_WebSocket_Violations(t *testing.T) {
+	defer os.Remove(Debug)
+	Initialize(true)
+
+	// Setup test server
+	done := make(chan struct{})
+	BindGet("/ws", UpgradeWebSocket(WebSocketOptions{MaxMessageSize: 8}, func(c *RequestContext, ws *WebSocket) {
+		defer func() { done <- struct{}{} }()
+		for {
+			if _, _, err := ws.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	ts := httptest.NewServer(ServeHTTP())
+	defer ts.Close()
+
+	tests := []struct {
+		name string
+		send func(conn net.Conn)
+		code uint16
+	}{
+		{"too big", func(conn net.Conn) { writeClientFrame(conn, BinaryMessage, true, false, make([]byte, 16)) }, CloseMessageTooBig},
+		{"invalid utf8", func(conn net.Conn) { writeClientFrame(conn, TextMessage, true, false, []byte{0xff, 0xfe}) }, CloseInvalidPayload},
+		{"unmasked", func(conn net.Conn) { conn.Write([]byte{0x81, 0x01, 'a'}) }, CloseProtocolError},
+		{"unexpected continuation", func(conn net.Conn) { writeClientFrame(conn, continuationFrame, true, false, []byte("a")) }, CloseProtocolError},
+		{"uncompressed session", func(conn net.Conn) { writeClientFrame(conn, TextMessage, true, true, []byte("a")) }, CloseProtocolError},
+	}
+	for _, test := range tests {
+		conn, reader, _ := dialWebSocket(t, ts.URL, "/ws", nil)
+		test.send(conn)
+
+		opcode, _, payload := readServerFrame(t, reader)
+		if opcode != CloseMessage || len(payload) < 2 || binary.BigEndian.Uint16(payload) != test.code {
+			t.Errorf("%s: %s", test.name, string(payload))
+		}
+		conn.Close()
+		<-done
+	}
+}
+
+func Test_WebSocket_Handshake(t *testing.T) {
+	defer os.Remove(Debug)
+	Initialize(true)
+
+	// Setup test server
+	BindWebSocket("/ws", func(c *RequestContext, ws *WebSocket) {})
+	ts := httptest.NewServer(ServeHTTP())
+	defer ts.Close()
+
+	// Plain request
+	response, _ := http.Get(ts.URL + "/ws")
+	if response.StatusCode != 426 {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 426, response.StatusCode)
+	}
+
+	// Unsupported version
+	request, _ := http.NewRequest("GET", ts.URL+"/ws", nil)
+	request.Header.Set("Connection", "Upgrade")
+	request.Header.Set("Upgrade", "websocket")
+	request.Header.Set("Sec-WebSocket-Version", "8")
+	request.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	response, _ = http.DefaultClient.Do(request)
+	if version := response.Header.Get("Sec-WebSocket-Version"); response.StatusCode != 426 || version != "13" {
+		t.Errorf(expectedFormat.StringButFoundString, "13", version)
+	}
+
+	// Cross origin
+	request.Header.Set("Sec-WebSocket-Version", "13")
+	request.Header.Set("Origin", "https://attacker.com")
+	response, _ = http.DefaultClient.Do(request)
+	if response.StatusCode != http.StatusForbidden {
+		t.Errorf(expectedFormat.NumberButFoundNumber, http.StatusForbidden, response.StatusCode)
+	}
+}
+
+func Test_WebSocket_PanicAndKeepalive(t *testing.T) {
+	defer os.Remove(Debug)
+	Initialize(true)
+
+	// Setup test server
+	done := make(chan struct{})
+	BindGet("/ws", UpgradeWebSocket(WebSocketOptions{PingInterval: 10 * time.Millisecond}, func(c *RequestContext, ws *WebSocket) {
+		defer close(done)
+		ws.ReadMessage()
+		panic("unexpected")
+	}))
+	ts := httptest.NewServer(ServeHTTP())
+	defer ts.Close()
+
+	conn, reader, _ := dialWebSocket(t, ts.URL, "/ws", nil)
+	defer conn.Close()
+
+	if opcode, _, _ := readServerFrame(t, reader); opcode != PingMessage {
+		t.Errorf(expectedFormat.NumberButFoundNumber, PingMessage, opcode)
+	}
+
+	// Panic must close connection without HTTP response
+	writeClientFrame(conn, TextMessage, true, false, []byte("panic"))
+	for {
+		opcode, _, payload := readServerFrame(t, reader)
+		if opcode == PingMessage {
+			continue
+		}
+		if opcode != CloseMessage || binary.BigEndian.Uint16(payload) != CloseInternalError {
+			t.Errorf(expectedFormat.NumberButFoundNumber, CloseInternalError, binary.BigEndian.Uint16(payload))
+		}
+		break
+	}
+	if rest, _ := io.ReadAll(reader); len(rest) > 0 {
+		t.Errorf(expectedFormat.StringButFoundString, "", string(rest))
+	}
+	<-done
+}
+
+func Test_WebSocket_HandshakeWriteError(t *testing.T) {
+	server, client := net.Pipe()
+	client.Close()
+
+	request := httptest.NewRequest("GET", "/ws", nil)
+	request.Header.Set("Connection", "Upgrade")
+	request.Header.Set("Upgrade", "websocket")
+	request.Header.Set("Sec-WebSocket-Version", "13")
+	request.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	writer := &hijackRecorder{ResponseRecorder: httptest.NewRecorder(), conn: server}
+
+	// Handler must not run on connection that never received handshake
+	called := false
+	UpgradeWebSocket(WebSocketOptions{}, func(c *RequestContext, ws *WebSocket) {
+		called = true
+	})(CreateContext(writer, request))
+
+	if called {
+		t.Error("Expected handler had been skipped.")
+	}
+}
+
+func Test_WebSocket_CloseReason(t *testing.T) {
+	defer os.Remove(Debug)
+	Initialize(true)
+
+	// Setup test server
+	done := make(chan struct{})
+	BindWebSocket("/ws", func(c *RequestContext, ws *WebSocket) {
+		defer close(done)
+		ws.Close(CloseGoingAway, strings.Repeat("é", 100))
+	})
+	ts := httptest.NewServer(ServeHTTP())
+	defer ts.Close()
+
+	conn, reader, _ := dialWebSocket(t, ts.URL, "/ws", nil)
+	defer conn.Close()
+
+	// Reason is truncated on rune boundary
+	_, _, payload := readServerFrame(t, reader)
+	if reason := payload[2:]; len(reason) != 122 || !utf8.Valid(reason) {
+		t.Errorf(expectedFormat.NumberButFoundNumber, 122, len(reason))
+	}
+	<-done
+}
+
+// hijackRecorder describes a response recorder that hands over a prepared connection.
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+	conn net.Conn
+}
+
+// Hijack returns prepared connection.
+func (r *hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return r.conn, bufio.NewReadWriter(bufio.NewReader(r.conn), bufio.NewWriter(r.conn)), nil
+}
+
+// dialWebSocket connects to test server & sends handshake.
+func dialWebSocket(t *testing.T, serverURL string, path string, header http.Header) (net.Conn, *bufio.Reader, *http.Response) {
+	conn, err := net.Dial("tcp", strings.TrimPrefix(serverURL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request, _ := http.NewRequest("GET", serverURL+path, nil)
+	for name, values := range header {
+		request.Header[name] = values
+	}
+	request.Header.Set("Connection", "Upgrade")
+	request.Header.Set("Upgrade", "websocket")
+	request.Header.Set("Sec-WebSocket-Version", "13")
+	request.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	request.Write(conn)
+
+	reader := bufio.NewReader(conn)
+	response, err := http.ReadResponse(reader, request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return conn, reader, response
+}
+
+// writeClientFrame sends a masked frame.
+func writeClientFrame(conn net.Conn, opcode int, fin bool, rsv1 bool, payload []byte) {
+	head := byte(opcode)
+	if fin {
+		head |= 0x80
+	}
+	if rsv1 {
+		head |= 0x40
+	}
+
+	mask := []byte{1, 2, 3, 4}
+	frame := []byte{head, 0x80 | byte(len(payload))}
+	frame = append(frame, mask...)
+	for idx, b := range payload {
+		frame = append(frame, b^mask[idx%4])
+	}
+	conn.Write(frame)
+}
+
+// readServerFrame reads a small unmasked frame.
+func readServerFrame(t *testing.T, reader *bufio.Reader) (int, bool, []byte) {
+	var head [2]byte
+	if _, err := io.ReadFull(reader, head[:]); err != nil {
+		t.Fatal(err)
+	}
+
+	payload := make([]byte, head[1]&0x7f)
+	if _, err := io.ReadFull(reader, payload); err != nil {
+		t.Fatal(err)
+	}
+	return int(head[0] & 0x0f), head[0]&0x40 != 0, payload
+}
+
+// inflateTest decompresses server's message.
+func inflateTest(t *testing.T, payload []byte) []byte {
+	reader := flate.NewReader(io.MultiReader(bytes.NewReader(payload), bytes.NewReader([]byte{0x00, 0x00, 0xff, 0xff, 0x01, 0x00, 0x00, 0xff, 0xff})))
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}